@@ -22,6 +22,11 @@ func run() error {
 		return err
 	}
 
+	// "diff [source...]" previews the next sync instead of running the agent.
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		return app.Diff(context.Background(), cfg, os.Stdout, os.Args[2:]...)
+	}
+
 	application, err := app.New(cfg)
 	if err != nil {
 		return err
@@ -2,26 +2,266 @@ package ad
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"desa-agent/internal/adapters/directory"
 	"desa-agent/internal/config"
 	"desa-agent/internal/models"
 )
 
+const defaultUserFilter = "(&(objectCategory=person)(objectClass=user))"
+
+// userAccountControl flags, see MS-ADTS 2.2.16.
+const (
+	uacAccountDisable   = 0x2
+	uacLockout          = 0x10
+	uacPasswordExpired  = 0x800000
+	uacDontExpirePasswd = 0x10000
+)
+
+// accountNeverExpires is the accountExpires value of accounts without an
+// expiry date; 0 means the same.
+const accountNeverExpires = 0x7FFFFFFFFFFFFFFF
+
+var userAttributes = []string{
+	"objectGUID",
+	"sAMAccountName",
+	"userPrincipalName",
+	"mail",
+	"displayName",
+	"givenName",
+	"sn",
+	"telephoneNumber",
+	"department",
+	"title",
+	"manager",
+	"employeeID",
+	"physicalDeliveryOfficeName",
+	"userAccountControl",
+	"msDS-User-Account-Control-Computed",
+	"lockoutTime",
+	"accountExpires",
+	"pwdLastSet",
+	"lastLogonTimestamp",
+	"whenCreated",
+	"adminCount",
+	"servicePrincipalName",
+}
+
 type Adapter struct {
-	cfg config.IDPConfig
+	cfg    config.IDPConfig
+	client *directory.Client
 }
 
 func New(cfg config.IDPConfig) (*Adapter, error) {
-	return &Adapter{cfg: cfg}, nil
+	client, err := directory.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Adapter{cfg: cfg, client: client}, nil
 }
 
 func (a *Adapter) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
+	guid, err := guidFilterValue(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := directory.And(a.client.Filter(defaultUserFilter), "(objectGUID="+guid+")")
+	entries, err := a.client.Search(ctx, filter, userAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("search user %s: %w", userID, err)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	user := toUser(entries[0], time.Now())
+	return &user, nil
 }
 
 func (a *Adapter) ListUsers(ctx context.Context) ([]models.User, error) {
-	return nil, nil
+	entries, err := a.client.Search(ctx, a.client.Filter(defaultUserFilter), userAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+
+	now := time.Now()
+	users := make([]models.User, 0, len(entries))
+	for _, entry := range entries {
+		users = append(users, toUser(entry, now))
+	}
+
+	return users, nil
+}
+
+// Verify checks that the directory accepts the configured credentials.
+func (a *Adapter) Verify(ctx context.Context) error {
+	return a.client.Verify(ctx)
 }
 
 func (a *Adapter) Close() error {
+	a.client.Close()
 	return nil
 }
+
+func toUser(entry *ldap.Entry, now time.Time) models.User {
+	email := entry.GetAttributeValue("mail")
+	if email == "" {
+		email = entry.GetAttributeValue("userPrincipalName")
+	}
+
+	return models.User{
+		Status:  toStatus(entry, now),
+		IdpType: models.IdentityProviderTypeActiveDirectory,
+		PII: &models.UserPII{
+			SourceID:    formatGUID(entry.GetRawAttributeValue("objectGUID")),
+			Username:    entry.GetAttributeValue("sAMAccountName"),
+			Email:       email,
+			DisplayName: entry.GetAttributeValue("displayName"),
+			FirstName:   entry.GetAttributeValue("givenName"),
+			LastName:    entry.GetAttributeValue("sn"),
+			Phone:       entry.GetAttributeValue("telephoneNumber"),
+			Department:  entry.GetAttributeValue("department"),
+			Title:       entry.GetAttributeValue("title"),
+			ManagerID:   entry.GetAttributeValue("manager"),
+			EmployeeID:  entry.GetAttributeValue("employeeID"),
+			Location:    entry.GetAttributeValue("physicalDeliveryOfficeName"),
+			Attributes: []models.Attribute{
+				{Key: models.AttributeKeyDistinguishedName, Value: entry.DN},
+			},
+		},
+		// lastLogonTimestamp is replicated but only updated when older than
+		// about two weeks; lastLogon is exact but local to each DC.
+		LastLogonAt:       fileTimeAttribute(entry, "lastLogonTimestamp"),
+		PasswordLastSetAt: fileTimeAttribute(entry, "pwdLastSet"),
+		CreatedAt:         timeAttribute(entry, "whenCreated"),
+		// adminCount is set by SDProp on members of protected groups and is
+		// not cleared when they leave.
+		Privileged:     entry.GetAttributeValue("adminCount") == "1",
+		ServiceAccount: len(entry.GetAttributeValues("servicePrincipalName")) > 0,
+	}
+}
+
+// toStatus derives the account state from userAccountControl, accountExpires,
+// lockoutTime and pwdLastSet. The DC-computed flags are preferred when
+// returned, as they honour the domain lockout duration and password age;
+// lockoutTime stays set after a lockout lapses until the next logon.
+func toStatus(entry *ldap.Entry, now time.Time) models.UserStatus {
+	uac, err := strconv.ParseInt(entry.GetAttributeValue("userAccountControl"), 10, 64)
+	if err != nil {
+		return models.UserStatusUnspecified
+	}
+
+	if uac&uacAccountDisable != 0 {
+		return models.UserStatusDisabled
+	}
+
+	if expires, ok := parseFileTime(entry.GetAttributeValue("accountExpires")); ok &&
+		expires != accountNeverExpires && fileTimeToTime(expires).Before(now) {
+		return models.UserStatusExpired
+	}
+
+	computed, err := strconv.ParseInt(entry.GetAttributeValue("msDS-User-Account-Control-Computed"), 10, 64)
+	if err == nil {
+		switch {
+		case computed&uacLockout != 0:
+			return models.UserStatusLocked
+		case computed&uacPasswordExpired != 0:
+			return models.UserStatusPasswordExpired
+		}
+		return models.UserStatusActive
+	}
+
+	if _, ok := parseFileTime(entry.GetAttributeValue("lockoutTime")); ok {
+		return models.UserStatusLocked
+	}
+
+	// pwdLastSet is zeroed when the user must change the password at next
+	// logon.
+	if pwdLastSet, err := strconv.ParseInt(entry.GetAttributeValue("pwdLastSet"), 10, 64); err == nil &&
+		pwdLastSet == 0 && uac&uacDontExpirePasswd == 0 {
+		return models.UserStatusPasswordExpired
+	}
+
+	return models.UserStatusActive
+}
+
+func fileTimeAttribute(entry *ldap.Entry, attr string) *time.Time {
+	fileTime, ok := parseFileTime(entry.GetAttributeValue(attr))
+	if !ok || fileTime == accountNeverExpires {
+		return nil
+	}
+
+	t := fileTimeToTime(fileTime)
+	return &t
+}
+
+func timeAttribute(entry *ldap.Entry, attr string) *time.Time {
+	t, ok := directory.GeneralizedTime(entry, attr)
+	if !ok {
+		return nil
+	}
+	return &t
+}
+
+// parseFileTime parses a Windows FILETIME attribute, reporting false for
+// missing and zero values.
+func parseFileTime(value string) (int64, bool) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n == 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// fileTimeToTime converts 100-nanosecond intervals since 1601-01-01 UTC.
+func fileTimeToTime(fileTime int64) time.Time {
+	const epochDiff = 116444736000000000 // 1601 to 1970 in 100ns intervals
+	intervals := fileTime - epochDiff
+	return time.Unix(intervals/1e7, intervals%1e7*100).UTC()
+}
+
+// formatGUID renders a binary objectGUID in its canonical string form. The
+// first three groups are stored little-endian.
+func formatGUID(raw []byte) string {
+	if len(raw) != 16 {
+		return hex.EncodeToString(raw)
+	}
+
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(raw[0:4]),
+		binary.LittleEndian.Uint16(raw[4:6]),
+		binary.LittleEndian.Uint16(raw[6:8]),
+		raw[8:10],
+		raw[10:16],
+	)
+}
+
+// guidFilterValue converts a canonical GUID string into the escaped binary
+// form expected in an objectGUID filter.
+func guidFilterValue(guid string) (string, error) {
+	raw, err := hex.DecodeString(strings.ReplaceAll(guid, "-", ""))
+	if err != nil || len(raw) != 16 {
+		return "", fmt.Errorf("invalid objectGUID %q", guid)
+	}
+
+	binary.LittleEndian.PutUint32(raw[0:4], binary.BigEndian.Uint32(raw[0:4]))
+	binary.LittleEndian.PutUint16(raw[4:6], binary.BigEndian.Uint16(raw[4:6]))
+	binary.LittleEndian.PutUint16(raw[6:8], binary.BigEndian.Uint16(raw[6:8]))
+
+	var b strings.Builder
+	for _, c := range raw {
+		fmt.Fprintf(&b, "\\%02x", c)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,361 @@
+package directory
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/go-ldap/ldap/v3/gssapi"
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+)
+
+const (
+	pageSize    = 500
+	dialTimeout = 10 * time.Second
+)
+
+// permanentResultCodes are LDAP results that retrying with the same settings
+// cannot change. Busy, unavailable and network errors stay transient.
+var permanentResultCodes = []uint16{
+	ldap.LDAPResultInvalidCredentials,
+	ldap.LDAPResultInappropriateAuthentication,
+	ldap.LDAPResultInsufficientAccessRights,
+	ldap.LDAPResultStrongAuthRequired,
+	ldap.LDAPResultConfidentialityRequired,
+	ldap.LDAPResultNoSuchObject,
+	ldap.LDAPResultInvalidDNSyntax,
+}
+
+// Client runs user searches over the configured search bases of an LDAP
+// compatible directory. It is shared by the AD and LDAP adapters, which only
+// differ in filters and attribute mapping. Bound connections are pooled and
+// fail over between the configured or discovered servers.
+type Client struct {
+	cfg       config.IDPConfig
+	excluded  []*ldap.DN
+	tlsConfig *tls.Config
+	pool      *pool
+}
+
+func NewClient(cfg config.IDPConfig) (*Client, error) {
+	excluded := make([]*ldap.DN, 0, len(cfg.ExcludeDNSuffixes))
+	for _, suffix := range cfg.ExcludeDNSuffixes {
+		dn, err := ldap.ParseDN(suffix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excluded DN suffix %q: %w", suffix, err)
+		}
+		excluded = append(excluded, dn)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{cfg: cfg, excluded: excluded, tlsConfig: tlsConfig}
+	c.pool = newPool(cfg, c.dial)
+
+	return c, nil
+}
+
+// newTLSConfig builds the client TLS settings, or returns nil when TLS is off.
+func newTLSConfig(cfg config.IDPConfig) (*tls.Config, error) {
+	if cfg.TLS.Mode == config.TLSModeNone {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.TLS.ServerName,
+		MinVersion:         cfg.TLS.MinTLSVersion(),
+		InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+	}
+
+	if cfg.TLS.CAFile != "" {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(cfg.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read TLS CA file: %w", err)
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", cfg.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	if cfg.TLS.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.ClientCertFile, cfg.TLS.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(cfg.TLS.PinnedSHA256) > 0 {
+		pins := make(map[string]struct{}, len(cfg.TLS.PinnedSHA256))
+		for _, pin := range cfg.TLS.PinnedSHA256 {
+			pins[strings.ToLower(strings.ReplaceAll(pin, ":", ""))] = struct{}{}
+		}
+
+		// The pin replaces chain and hostname verification, which lets DCs
+		// with self-signed certificates be trusted individually.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("server sent no certificate")
+			}
+			sum := sha256.Sum256(rawCerts[0])
+			if _, ok := pins[hex.EncodeToString(sum[:])]; !ok {
+				return fmt.Errorf("server certificate %x is not pinned", sum)
+			}
+			return nil
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// Filter returns the configured user filter, or defaultFilter when none is set.
+func (c *Client) Filter(defaultFilter string) string {
+	if c.cfg.UserFilter != "" {
+		return c.cfg.UserFilter
+	}
+	return defaultFilter
+}
+
+// Search runs filter against every search base and returns the entries that
+// are not excluded by DN suffix. A search interrupted by a dropped connection
+// is retried once on a fresh one.
+func (c *Client) Search(ctx context.Context, filter string, attributes []string) ([]*ldap.Entry, error) {
+	entries, err := c.search(ctx, filter, attributes)
+	if ldap.IsErrorWithCode(err, ldap.ErrorNetwork) && ctx.Err() == nil {
+		entries, err = c.search(ctx, filter, attributes)
+	}
+	return entries, err
+}
+
+func (c *Client) search(ctx context.Context, filter string, attributes []string) ([]*ldap.Entry, error) {
+	conn, err := c.pool.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.searchBases(ctx, conn.Conn, filter, attributes)
+	c.pool.put(conn, ldap.IsErrorWithCode(err, ldap.ErrorNetwork))
+	return entries, err
+}
+
+// searchBases returns each entry once, as overlapping search bases return
+// the entries they share from every one of them.
+func (c *Client) searchBases(ctx context.Context, conn *ldap.Conn, filter string, attributes []string) ([]*ldap.Entry, error) {
+	var entries []*ldap.Entry
+	seen := make(map[string]struct{})
+	for _, base := range c.cfg.Bases() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		req := ldap.NewSearchRequest(
+			base.DN,
+			toLDAPScope(base.Scope),
+			ldap.NeverDerefAliases,
+			0, 0, false,
+			filter,
+			attributes,
+			nil,
+		)
+
+		result, err := conn.SearchWithPaging(req, pageSize)
+		if err != nil {
+			return nil, classify(fmt.Errorf("search %q: %w", base.DN, err))
+		}
+
+		for _, entry := range result.Entries {
+			if c.Excluded(entry.DN) {
+				continue
+			}
+			// DNs compare case-insensitively.
+			dn := strings.ToLower(entry.DN)
+			if _, ok := seen[dn]; ok {
+				continue
+			}
+			seen[dn] = struct{}{}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// Excluded reports whether dn lies under one of the excluded DN suffixes.
+func (c *Client) Excluded(dn string) bool {
+	if len(c.excluded) == 0 {
+		return false
+	}
+
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return false
+	}
+
+	for _, suffix := range c.excluded {
+		if suffix.EqualFold(parsed) || suffix.AncestorOfFold(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// dial opens and binds a connection to one server.
+func (c *Client) dial(ctx context.Context, s server) (*ldap.Conn, error) {
+	url := s.url
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+
+	var tlsConfig *tls.Config
+	opts := []ldap.DialOpt{ldap.DialWithDialer(dialer)}
+	if c.tlsConfig != nil {
+		tlsConfig = c.tlsConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = s.host
+		}
+		opts = append(opts, ldap.DialWithTLSConfig(tlsConfig))
+	}
+
+	conn, err := ldap.DialURL(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", url, err)
+	}
+
+	if c.cfg.TLS.Mode == config.TLSModeStartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("start TLS with %s: %w", url, err)
+		}
+	}
+
+	if err := c.bind(conn, s.host); err != nil {
+		conn.Close()
+		return nil, classify(fmt.Errorf("%s: %w", url, err))
+	}
+
+	return conn, nil
+}
+
+func (c *Client) bind(conn *ldap.Conn, host string) error {
+	switch c.cfg.BindMechanism {
+	case config.BindMechanismGSSAPI:
+		krb := c.cfg.Kerberos
+		// AD does not support FAST pre-authentication negotiation.
+		krbClient, err := gssapi.NewClientWithKeytab(krb.Principal, krb.Realm, krb.KeytabFile, krb.ConfigFile,
+			krbclient.DisablePAFXFAST(true))
+		if err != nil {
+			return models.Permanent(fmt.Errorf("load kerberos keytab for %s: %w", krb.Principal, err))
+		}
+		defer krbClient.Close()
+
+		spn := c.cfg.ServicePrincipal(host)
+		if err := conn.GSSAPIBind(krbClient, spn, ""); err != nil {
+			return fmt.Errorf("gssapi bind as %s for %s: %w", krb.Principal, spn, err)
+		}
+	case config.BindMechanismExternal:
+		if err := conn.ExternalBind(); err != nil {
+			return fmt.Errorf("external bind with client certificate %s: %w", c.cfg.TLS.ClientCertFile, err)
+		}
+	default:
+		if c.cfg.BindDN == "" {
+			return nil
+		}
+		if err := conn.Bind(c.cfg.BindDN, c.cfg.BindPass); err != nil {
+			return fmt.Errorf("bind as %s: %w", c.cfg.BindDN, err)
+		}
+	}
+
+	return nil
+}
+
+// Verify connects and binds once, so that unusable credentials surface at
+// startup rather than on the first sync. The connection is kept in the pool.
+func (c *Client) Verify(ctx context.Context) error {
+	conn, err := c.pool.get(ctx)
+	if err != nil {
+		return err
+	}
+	c.pool.put(conn, false)
+	return nil
+}
+
+// Close closes the pooled connections.
+func (c *Client) Close() {
+	c.pool.close()
+}
+
+// classify marks err as permanent when the directory rejected the request in
+// a way that retrying cannot fix.
+func classify(err error) error {
+	if ldap.IsErrorAnyOf(err, permanentResultCodes...) {
+		return models.Permanent(err)
+	}
+	return err
+}
+
+// And combines filters with a logical AND, skipping empty ones.
+func And(filters ...string) string {
+	var b strings.Builder
+	n := 0
+	for _, f := range filters {
+		if f == "" {
+			continue
+		}
+		b.WriteString(f)
+		n++
+	}
+	if n == 1 {
+		return b.String()
+	}
+	return "(&" + b.String() + ")"
+}
+
+// GeneralizedTime parses a GeneralizedTime attribute such as whenCreated or
+// createTimestamp into UTC, reporting false when it is missing or malformed.
+func GeneralizedTime(entry *ldap.Entry, attr string) (time.Time, bool) {
+	value := entry.GetRawAttributeValue(attr)
+	if len(value) == 0 {
+		return time.Time{}, false
+	}
+
+	t, err := ber.ParseGeneralizedTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
+
+func toLDAPScope(scope config.SearchScope) int {
+	switch scope {
+	case config.SearchScopeBase:
+		return ldap.ScopeBaseObject
+	case config.SearchScopeOne:
+		return ldap.ScopeSingleLevel
+	default:
+		return ldap.ScopeWholeSubtree
+	}
+}
@@ -0,0 +1,329 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"desa-agent/internal/config"
+)
+
+const (
+	// idleTimeout drops pooled connections before servers close them; AD
+	// closes idle connections after 15 minutes by default.
+	idleTimeout = 5 * time.Minute
+	// srvRefresh is how long discovered servers are reused before DNS is
+	// queried again.
+	srvRefresh = 5 * time.Minute
+)
+
+// server is a directory endpoint.
+type server struct {
+	url  string
+	host string
+}
+
+type pooledConn struct {
+	*ldap.Conn
+	server   server
+	lastUsed time.Time
+}
+
+// pool keeps up to cfg.PoolSize idle bound connections and fails over
+// between servers. A server that cannot be dialled or bound, or that drops a
+// connection in use, is skipped for cfg.ServerRetryInterval and its idle
+// connections are closed. While connections go to a fallback server, the
+// preferred ones are rechecked at the same interval to fail back.
+type pool struct {
+	cfg  config.IDPConfig
+	dial func(ctx context.Context, s server) (*ldap.Conn, error)
+
+	mu           sync.Mutex
+	idle         []*pooledConn
+	downUntil    map[string]time.Time
+	discovered   []server
+	discoveredAt time.Time
+	// preferred lists the servers in order as last resolved, and recheckAt
+	// is when ones preferred over the current server are dialled again.
+	preferred []server
+	recheckAt time.Time
+	closed    bool
+}
+
+func newPool(cfg config.IDPConfig, dial func(ctx context.Context, s server) (*ldap.Conn, error)) *pool {
+	return &pool{cfg: cfg, dial: dial, downUntil: make(map[string]time.Time)}
+}
+
+// get returns an idle connection that is still open, or dials a new one.
+// Idle connections to a server marked down are dropped.
+func (p *pool) get(ctx context.Context) (*pooledConn, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errors.New("connection pool is closed")
+	}
+	now := time.Now()
+	for len(p.idle) > 0 {
+		pc := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if !pc.IsClosing() && now.Sub(pc.lastUsed) < idleTimeout && !p.isDown(pc.server, now) {
+			recheck := p.fallback(pc.server) && !now.Before(p.recheckAt)
+			if recheck {
+				p.recheckAt = now.Add(p.cfg.ServerRetryInterval)
+			}
+			p.mu.Unlock()
+
+			if recheck {
+				return p.failBack(ctx, pc), nil
+			}
+			return pc, nil
+		}
+		pc.Close()
+	}
+	p.mu.Unlock()
+
+	return p.connect(ctx)
+}
+
+// put returns a connection to the pool. Broken connections, and those beyond
+// the pool size, are closed. A broken connection marks its server down, as
+// its other connections are likely broken too.
+func (p *pool) put(pc *pooledConn, broken bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if broken {
+		pc.Close()
+		p.downUntil[pc.server.url] = time.Now().Add(p.cfg.ServerRetryInterval)
+		p.drain(pc.server)
+		return
+	}
+
+	if p.closed || pc.IsClosing() || len(p.idle) >= p.cfg.PoolSize {
+		pc.Close()
+		return
+	}
+
+	pc.lastUsed = time.Now()
+	p.idle = append(p.idle, pc)
+}
+
+// drain closes the idle connections to s. p.mu must be held.
+func (p *pool) drain(s server) {
+	idle := p.idle[:0]
+	for _, pc := range p.idle {
+		if pc.server.url == s.url {
+			pc.Close()
+			continue
+		}
+		idle = append(idle, pc)
+	}
+	clear(p.idle[len(idle):])
+	p.idle = idle
+}
+
+// isDown reports whether s is out of rotation. p.mu must be held.
+func (p *pool) isDown(s server, now time.Time) bool {
+	return now.Before(p.downUntil[s.url])
+}
+
+// fallback reports whether servers preferred over s are configured. p.mu
+// must be held.
+func (p *pool) fallback(s server) bool {
+	return len(p.preferred) > 0 && p.preferred[0].url != s.url
+}
+
+// failBack dials the servers preferred over the one pc is connected to. On
+// success the idle connections to the fallback server are closed together
+// with pc and the new connection is returned; otherwise pc is.
+func (p *pool) failBack(ctx context.Context, pc *pooledConn) *pooledConn {
+	p.mu.Lock()
+	var candidates []server
+	now := time.Now()
+	for _, s := range p.preferred {
+		if s.url == pc.server.url {
+			break
+		}
+		if !p.isDown(s, now) {
+			candidates = append(candidates, s)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, s := range candidates {
+		conn, err := p.dial(ctx, s)
+		if err != nil {
+			p.markDown(s)
+			continue
+		}
+		p.markUp(s)
+
+		p.mu.Lock()
+		p.drain(pc.server)
+		p.mu.Unlock()
+		pc.Close()
+
+		return &pooledConn{Conn: conn, server: s}
+	}
+
+	return pc
+}
+
+func (p *pool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for _, pc := range p.idle {
+		pc.Close()
+	}
+	p.idle = nil
+}
+
+// connect tries the servers in order, healthy ones first. Servers marked
+// down are still tried last so that an outage of every server ends as soon
+// as one recovers.
+func (p *pool) connect(ctx context.Context) (*pooledConn, error) {
+	servers, err := p.servers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.preferred = servers
+	p.mu.Unlock()
+
+	var errs []error
+	for _, s := range p.order(servers) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		conn, err := p.dial(ctx, s)
+		if err != nil {
+			p.markDown(s)
+			errs = append(errs, err)
+			continue
+		}
+
+		p.markUp(s)
+		return &pooledConn{Conn: conn, server: s}, nil
+	}
+
+	return nil, fmt.Errorf("no directory server available: %w", errors.Join(errs...))
+}
+
+func (p *pool) order(servers []server) []server {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	ordered := make([]server, 0, len(servers))
+	var down []server
+	for _, s := range servers {
+		if p.isDown(s, now) {
+			down = append(down, s)
+			continue
+		}
+		ordered = append(ordered, s)
+	}
+	return append(ordered, down...)
+}
+
+func (p *pool) markDown(s server) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.downUntil[s.url] = time.Now().Add(p.cfg.ServerRetryInterval)
+}
+
+func (p *pool) markUp(s server) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.downUntil, s.url)
+}
+
+// servers returns the configured servers, the ones discovered through DNS,
+// or the single Host and Port.
+func (p *pool) servers(ctx context.Context) ([]server, error) {
+	switch {
+	case len(p.cfg.Servers) > 0:
+		servers := make([]server, 0, len(p.cfg.Servers))
+		for _, raw := range p.cfg.Servers {
+			u, err := url.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid server url %q: %w", raw, err)
+			}
+			servers = append(servers, server{url: raw, host: u.Hostname()})
+		}
+		return servers, nil
+	case p.cfg.DiscoveryDomain != "":
+		return p.discover(ctx)
+	default:
+		return []server{p.server(p.cfg.Host, p.cfg.Port)}, nil
+	}
+}
+
+// discover looks up the SRV records of the discovery domain. Records come
+// back ordered by priority and shuffled by weight, which spreads sources
+// across servers of equal priority.
+func (p *pool) discover(ctx context.Context) ([]server, error) {
+	p.mu.Lock()
+	if len(p.discovered) > 0 && time.Since(p.discoveredAt) < srvRefresh {
+		servers := p.discovered
+		p.mu.Unlock()
+		return servers, nil
+	}
+	p.mu.Unlock()
+
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", p.cfg.SRVName())
+	if err != nil {
+		return nil, fmt.Errorf("discover servers via %s: %w", p.cfg.SRVName(), err)
+	}
+
+	servers := make([]server, 0, len(records))
+	for _, record := range records {
+		host := trimDot(record.Target)
+		port := int(record.Port)
+		// SRV records advertise the plain LDAP port.
+		if p.cfg.TLS.Mode == config.TLSModeLDAPS {
+			port = p.cfg.Port
+		}
+		servers = append(servers, p.server(host, port))
+	}
+
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("discover servers via %s: no records", p.cfg.SRVName())
+	}
+
+	p.mu.Lock()
+	p.discovered = servers
+	p.discoveredAt = time.Now()
+	p.mu.Unlock()
+
+	return servers, nil
+}
+
+func (p *pool) server(host string, port int) server {
+	scheme := "ldap"
+	if p.cfg.TLS.Mode == config.TLSModeLDAPS {
+		scheme = "ldaps"
+	}
+	return server{
+		url:  scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port)),
+		host: host,
+	}
+}
+
+func trimDot(host string) string {
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		return host[:len(host)-1]
+	}
+	return host
+}
@@ -4,7 +4,12 @@ import (
 	"fmt"
 
 	"desa-agent/internal/adapters/ad"
+	"desa-agent/internal/adapters/file"
+	"desa-agent/internal/adapters/google"
+	"desa-agent/internal/adapters/graph"
+	"desa-agent/internal/adapters/hr"
 	"desa-agent/internal/adapters/ldap"
+	"desa-agent/internal/adapters/scim"
 	"desa-agent/internal/config"
 )
 
@@ -14,6 +19,16 @@ func NewIdentityProvider(cfg config.IDPConfig) (IdentityProvider, error) {
 		return ad.New(cfg)
 	case config.IdentityProviderTypeLDAP:
 		return ldap.New(cfg)
+	case config.IdentityProviderTypeSCIM:
+		return scim.New(cfg)
+	case config.IdentityProviderTypeEntraID:
+		return graph.New(cfg)
+	case config.IdentityProviderTypeGoogleWorkspace:
+		return google.New(cfg)
+	case config.IdentityProviderTypeFile:
+		return file.New(cfg)
+	case config.IdentityProviderTypeHR:
+		return hr.New(cfg)
 	default:
 		return nil, fmt.Errorf("unsupported identity provider type: %s", cfg.Type)
 	}
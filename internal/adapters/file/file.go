@@ -0,0 +1,297 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+)
+
+// Adapter reads users from a CSV or JSON Lines export and watches the file
+// for changes, so a new export is picked up without waiting for the next
+// sync tick.
+type Adapter struct {
+	cfg     config.IDPConfig
+	changes chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func New(cfg config.IDPConfig) (*Adapter, error) {
+	info, err := os.Stat(cfg.File.Path)
+	if err != nil {
+		return nil, fmt.Errorf("stat user file: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &Adapter{
+		cfg:     cfg,
+		changes: make(chan struct{}, 1),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go a.watch(ctx, info)
+
+	return a, nil
+}
+
+func (a *Adapter) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	users, err := a.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if u.PII.SourceID == userID {
+			return &u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (a *Adapter) ListUsers(ctx context.Context) ([]models.User, error) {
+	f, err := os.Open(a.cfg.File.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open user file: %w", err)
+	}
+	defer f.Close()
+
+	var records []map[string]string
+	switch a.cfg.File.Format {
+	case config.FileFormatJSONL:
+		records, err = readJSONL(f)
+	default:
+		records, err = readCSV(f, a.cfg.File.Delimiter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read user file: %w", err)
+	}
+
+	users := make([]models.User, 0, len(records))
+	seen := make(map[string]struct{}, len(records))
+	for i, record := range records {
+		u := a.toUser(record)
+		if u.PII.SourceID == "" {
+			return nil, fmt.Errorf("record %d: missing %s", i+1, a.column("source_id"))
+		}
+		if _, ok := seen[u.PII.SourceID]; ok {
+			return nil, fmt.Errorf("record %d: duplicate %s %q", i+1, a.column("source_id"), u.PII.SourceID)
+		}
+		seen[u.PII.SourceID] = struct{}{}
+		users = append(users, u)
+	}
+
+	return users, nil
+}
+
+// Changes signals whenever the file's size or modification time changes
+// and then holds for another poll, so that an export still being written is
+// not read.
+func (a *Adapter) Changes() <-chan struct{} {
+	return a.changes
+}
+
+func (a *Adapter) Close() error {
+	a.cancel()
+	<-a.done
+	return nil
+}
+
+func (a *Adapter) watch(ctx context.Context, last os.FileInfo) {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.cfg.File.PollInterval)
+	defer ticker.Stop()
+
+	// pending is the changed state seen on the previous poll.
+	var pending os.FileInfo
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A missing file is usually an export being replaced; wait for
+			// the new one rather than reporting an empty user set.
+			info, err := os.Stat(a.cfg.File.Path)
+			if err != nil {
+				pending = nil
+				continue
+			}
+
+			if sameState(info, last) {
+				pending = nil
+				continue
+			}
+			if pending == nil || !sameState(info, pending) {
+				pending = info
+				continue
+			}
+			last, pending = info, nil
+
+			select {
+			case a.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func sameState(a, b os.FileInfo) bool {
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// column returns the CSV header or JSON key the field is read from.
+func (a *Adapter) column(field string) string {
+	if column, ok := a.cfg.File.Mapping[field]; ok {
+		return column
+	}
+	return field
+}
+
+func (a *Adapter) toUser(record map[string]string) models.User {
+	get := func(field string) string {
+		return strings.TrimSpace(record[a.column(field)])
+	}
+
+	return models.User{
+		Status:  a.toStatus(get("status")),
+		IdpType: models.IdentityProviderTypeFile,
+		PII: &models.UserPII{
+			SourceID:    get("source_id"),
+			Username:    get("username"),
+			Email:       get("email"),
+			DisplayName: get("display_name"),
+			FirstName:   get("first_name"),
+			LastName:    get("last_name"),
+			Phone:       get("phone"),
+			Department:  get("department"),
+			Title:       get("title"),
+			ManagerID:   get("manager_id"),
+			EmployeeID:  get("employee_id"),
+			Location:    get("location"),
+		},
+	}
+}
+
+// toStatus treats users as active unless the status column holds one of the
+// configured disabled values.
+func (a *Adapter) toStatus(value string) models.UserStatus {
+	if slices.ContainsFunc(a.cfg.File.DisabledValues, func(v string) bool {
+		return strings.EqualFold(v, value)
+	}) {
+		return models.UserStatusDisabled
+	}
+	return models.UserStatusActive
+}
+
+func readCSV(r io.Reader, delimiter rune) ([]map[string]string, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.Comma = delimiter
+	reader.TrimLeadingSpace = true
+
+	// An empty file is more likely a truncated export than a source
+	// without users, and syncing it would remove every user.
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	// Spreadsheet exports often start with a byte order mark.
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var records []map[string]string
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				record[column] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("file has a header but no rows")
+	}
+
+	return records, nil
+}
+
+func readJSONL(r io.Reader) ([]map[string]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var records []map[string]string
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var raw map[string]any
+		if err := json.Unmarshal([]byte(data), &raw); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		record := make(map[string]string, len(raw))
+		for key, value := range raw {
+			record[key] = stringValue(value)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("file has no records")
+	}
+
+	return records, nil
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,85 @@
+package file_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"desa-agent/internal/adapters/file"
+	"desa-agent/internal/config"
+)
+
+func newAdapter(t *testing.T, content string) (*file.Adapter, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write user file: %v", err)
+	}
+
+	adapter, err := file.New(config.IDPConfig{
+		File: config.FileConfig{
+			Path:         path,
+			Delimiter:    ',',
+			PollInterval: 10 * time.Millisecond,
+			Mapping:      map[string]string{"source_id": "id"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("file.New: %v", err)
+	}
+	t.Cleanup(func() { _ = adapter.Close() })
+	return adapter, path
+}
+
+func TestListUsersRejectsEmptyExport(t *testing.T) {
+	for _, tc := range []struct{ name, content string }{
+		{"empty", ""},
+		{"header only", "id,email\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			adapter, _ := newAdapter(t, tc.content)
+
+			users, err := adapter.ListUsers(context.Background())
+			if err == nil {
+				t.Errorf("ListUsers = %d users, want an error", len(users))
+			}
+		})
+	}
+}
+
+func TestChangesWaitForExportToSettle(t *testing.T) {
+	adapter, path := newAdapter(t, "id,email\n1,a@example.com\n")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open user file: %v", err)
+	}
+	defer f.Close()
+
+	// Keep growing the file faster than it is polled.
+	writing := time.After(150 * time.Millisecond)
+	ticker := time.NewTicker(3 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 2; ; i++ {
+		select {
+		case <-adapter.Changes():
+			t.Fatal("change signalled while the export was still being written")
+		case <-ticker.C:
+			if _, err := f.WriteString("2,b@example.com\n"); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			continue
+		case <-writing:
+		}
+		break
+	}
+
+	select {
+	case <-adapter.Changes():
+	case <-time.After(time.Second):
+		t.Fatal("no change signalled after the export settled")
+	}
+}
@@ -0,0 +1,333 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+)
+
+const (
+	requestTimeout  = 30 * time.Second
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+	directoryScope  = "https://www.googleapis.com/auth/admin.directory.user.readonly"
+)
+
+// Adapter reads users of a Google Workspace domain through the Admin SDK
+// Directory API, authenticating as a service account with domain-wide
+// delegation.
+type Adapter struct {
+	cfg     config.IDPConfig
+	baseURL string
+	client  *http.Client
+}
+
+// serviceAccountKey is the subset of a service account JSON key the adapter
+// needs.
+type serviceAccountKey struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+func New(cfg config.IDPConfig) (*Adapter, error) {
+	data, err := os.ReadFile(cfg.Google.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read google credentials: %w", err)
+	}
+
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("parse google credentials: %w", err)
+	}
+
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("google credentials must contain client_email and private_key")
+	}
+
+	tokenURL := cfg.Google.TokenURL
+	if tokenURL == "" {
+		tokenURL = key.TokenURI
+	}
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	jwtConfig := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Subject:      cfg.Google.Subject,
+		Scopes:       []string{directoryScope},
+		TokenURL:     tokenURL,
+	}
+
+	client := jwtConfig.Client(context.Background())
+	client.Timeout = requestTimeout
+
+	return &Adapter{
+		cfg:     cfg,
+		baseURL: strings.TrimRight(cfg.Google.BaseURL, "/") + "/admin/directory/v1",
+		client:  client,
+	}, nil
+}
+
+func (a *Adapter) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	var u user
+	found, err := a.get(ctx, "/users/"+url.PathEscape(userID), url.Values{"projection": {"basic"}}, &u)
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", userID, err)
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	result := u.toUser()
+	return &result, nil
+}
+
+// ListUsers pages through users.list with pageToken until the last page.
+func (a *Adapter) ListUsers(ctx context.Context) ([]models.User, error) {
+	query := url.Values{
+		"projection": {"basic"},
+		"maxResults": {strconv.Itoa(a.cfg.Google.PageSize)},
+	}
+	if a.cfg.Google.Domain != "" {
+		query.Set("domain", a.cfg.Google.Domain)
+	} else {
+		query.Set("customer", a.cfg.Google.Customer)
+	}
+
+	var users []models.User
+	for {
+		var page userPage
+		if _, err := a.get(ctx, "/users", query, &page); err != nil {
+			return nil, fmt.Errorf("list users: %w", err)
+		}
+
+		for _, u := range page.Users {
+			users = append(users, u.toUser())
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+
+	return users, nil
+}
+
+func (a *Adapter) Close() error {
+	a.client.CloseIdleConnections()
+	return nil
+}
+
+// get fetches path and decodes the JSON body into out. It reports false
+// without an error when the resource does not exist.
+func (a *Adapter) get(ctx context.Context, path string, query url.Values, out any) (bool, error) {
+	target := a.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		// A rejected token request means a wrong key, subject or missing
+		// domain-wide delegation.
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil && retrieveErr.Response.StatusCode < 500 {
+			return false, models.Permanent(err)
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return false, models.Permanent(err)
+		}
+		return false, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decode response: %w", err)
+	}
+
+	return true, nil
+}
+
+type userPage struct {
+	Users         []user `json:"users"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+type user struct {
+	ID           string `json:"id"`
+	PrimaryEmail string `json:"primaryEmail"`
+	Name         struct {
+		GivenName  string `json:"givenName"`
+		FamilyName string `json:"familyName"`
+		FullName   string `json:"fullName"`
+	} `json:"name"`
+	Suspended        bool           `json:"suspended"`
+	Archived         bool           `json:"archived"`
+	IsAdmin          bool           `json:"isAdmin"`
+	IsDelegatedAdmin bool           `json:"isDelegatedAdmin"`
+	IsEnrolledIn2Sv  *bool          `json:"isEnrolledIn2Sv"`
+	LastLoginTime    string         `json:"lastLoginTime"`
+	CreationTime     string         `json:"creationTime"`
+	Organizations    []organization `json:"organizations"`
+	Phones           []typedValue   `json:"phones"`
+	Locations        []location     `json:"locations"`
+	ExternalIDs      []typedValue   `json:"externalIds"`
+	Relations        []typedValue   `json:"relations"`
+}
+
+type organization struct {
+	Title      string `json:"title"`
+	Department string `json:"department"`
+	Primary    bool   `json:"primary"`
+}
+
+type typedValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Primary bool   `json:"primary"`
+}
+
+type location struct {
+	Area       string `json:"area"`
+	BuildingID string `json:"buildingId"`
+	FloorName  string `json:"floorName"`
+	Type       string `json:"type"`
+}
+
+func (u user) toUser() models.User {
+	pii := &models.UserPII{
+		SourceID:    u.ID,
+		Username:    u.PrimaryEmail,
+		Email:       u.PrimaryEmail,
+		DisplayName: u.Name.FullName,
+		FirstName:   u.Name.GivenName,
+		LastName:    u.Name.FamilyName,
+		Phone:       primaryValue(u.Phones, ""),
+		EmployeeID:  primaryValue(u.ExternalIDs, "organization"),
+		ManagerID:   primaryValue(u.Relations, "manager"),
+		Location:    primaryLocation(u.Locations),
+	}
+
+	if org := primaryOrganization(u.Organizations); org != nil {
+		pii.Department = org.Department
+		pii.Title = org.Title
+	}
+
+	return models.User{
+		Status:  toStatus(u),
+		IdpType: models.IdentityProviderTypeGoogleWorkspace,
+		PII:     pii,
+
+		LastLogonAt: parseTime(u.LastLoginTime),
+		CreatedAt:   parseTime(u.CreationTime),
+		Privileged:  u.IsAdmin || u.IsDelegatedAdmin,
+		MFAEnabled:  u.IsEnrolledIn2Sv,
+	}
+}
+
+// parseTime parses an RFC 3339 timestamp. Users who never signed in report
+// the Unix epoch as lastLoginTime.
+func parseTime(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil || t.Unix() <= 0 {
+		return nil
+	}
+
+	t = t.UTC()
+	return &t
+}
+
+func toStatus(u user) models.UserStatus {
+	if u.Suspended || u.Archived {
+		return models.UserStatusDisabled
+	}
+	return models.UserStatusActive
+}
+
+func primaryOrganization(orgs []organization) *organization {
+	for i := range orgs {
+		if orgs[i].Primary {
+			return &orgs[i]
+		}
+	}
+	if len(orgs) > 0 {
+		return &orgs[0]
+	}
+	return nil
+}
+
+// primaryValue returns the primary value of the given type, falling back to
+// the first one. An empty valueType matches any type.
+func primaryValue(values []typedValue, valueType string) string {
+	var first string
+	for _, v := range values {
+		if valueType != "" && v.Type != valueType {
+			continue
+		}
+		if v.Primary {
+			return v.Value
+		}
+		if first == "" {
+			first = v.Value
+		}
+	}
+	return first
+}
+
+func primaryLocation(locations []location) string {
+	for _, l := range locations {
+		if l.Type == "desk" {
+			return formatLocation(l)
+		}
+	}
+	if len(locations) > 0 {
+		return formatLocation(locations[0])
+	}
+	return ""
+}
+
+func formatLocation(l location) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{l.Area, l.BuildingID, l.FloorName} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
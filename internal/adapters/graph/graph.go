@@ -0,0 +1,286 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+)
+
+const requestTimeout = 30 * time.Second
+
+// userSelect lists the properties requested for every user. The manager is
+// left out on purpose: delta queries cannot expand it, and full and
+// incremental syncs must produce identical records.
+const userSelect = "id,userPrincipalName,mail,displayName,givenName,surname,businessPhones," +
+	"mobilePhone,department,jobTitle,employeeId,officeLocation,accountEnabled"
+
+// errDeltaExpired is returned when Graph no longer recognises a delta token.
+var errDeltaExpired = errors.New("delta token expired")
+
+// Adapter reads users of an Entra ID tenant through Microsoft Graph using
+// OAuth2 client credentials.
+type Adapter struct {
+	cfg     config.IDPConfig
+	baseURL string
+	client  *http.Client
+
+	mu        sync.Mutex
+	deltaLink string
+}
+
+func New(cfg config.IDPConfig) (*Adapter, error) {
+	baseURL := strings.TrimRight(cfg.Graph.BaseURL, "/")
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid graph base url: %w", err)
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     cfg.Graph.ClientID,
+		ClientSecret: cfg.Graph.ClientSecret,
+		TokenURL:     cfg.Graph.TokenEndpoint(),
+		Scopes:       []string{base.Scheme + "://" + base.Host + "/.default"},
+	}
+
+	client := credentials.Client(context.Background())
+	client.Timeout = requestTimeout
+
+	return &Adapter{cfg: cfg, baseURL: baseURL, client: client}, nil
+}
+
+func (a *Adapter) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	query := url.Values{"$select": {userSelect}}
+
+	var u user
+	status, err := a.get(ctx, a.baseURL+"/users/"+url.PathEscape(userID)+"?"+query.Encode(), &u)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", userID, err)
+	}
+
+	result := u.toUser()
+	return &result, nil
+}
+
+// ListUsers reads /users, following @odata.nextLink until the last page.
+func (a *Adapter) ListUsers(ctx context.Context) ([]models.User, error) {
+	query := url.Values{
+		"$select": {userSelect},
+		"$top":    {strconv.Itoa(a.cfg.Graph.PageSize)},
+	}
+
+	var users []models.User
+	for link := a.baseURL + "/users?" + query.Encode(); link != ""; {
+		var page userPage
+		if _, err := a.get(ctx, link, &page); err != nil {
+			return nil, fmt.Errorf("list users: %w", err)
+		}
+
+		for _, u := range page.Value {
+			users = append(users, u.toUser())
+		}
+		link = page.NextLink
+	}
+
+	return users, nil
+}
+
+// ListUserChanges reads /users/delta from the delta link kept by the previous
+// call. The first round, or one following an expired token, returns every
+// user as changed and establishes the baseline.
+func (a *Adapter) ListUserChanges(ctx context.Context) (*models.UserChanges, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	changes, deltaLink, err := a.readDelta(ctx, a.deltaLink)
+	if errors.Is(err, errDeltaExpired) {
+		changes, deltaLink, err = a.readDelta(ctx, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.deltaLink = deltaLink
+	return changes, nil
+}
+
+func (a *Adapter) readDelta(ctx context.Context, deltaLink string) (*models.UserChanges, string, error) {
+	initial := deltaLink == ""
+
+	link := deltaLink
+	if initial {
+		link = a.baseURL + "/users/delta?" + url.Values{"$select": {userSelect}}.Encode()
+	}
+
+	changes := &models.UserChanges{}
+	var changedIDs []string
+	for {
+		var page userPage
+		status, err := a.get(ctx, link, &page)
+		if status == http.StatusGone {
+			return nil, "", errDeltaExpired
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("read user delta: %w", err)
+		}
+
+		for _, u := range page.Value {
+			switch {
+			case u.Removed != nil:
+				changes.RemovedIDs = append(changes.RemovedIDs, u.ID)
+			case initial:
+				changes.Changed = append(changes.Changed, u.toUser())
+			default:
+				changedIDs = append(changedIDs, u.ID)
+			}
+		}
+
+		if page.NextLink == "" {
+			deltaLink = page.DeltaLink
+			break
+		}
+		link = page.NextLink
+	}
+
+	// Later rounds may only carry the properties that changed, so changed
+	// users are read in full.
+	for _, id := range changedIDs {
+		u, err := a.GetUser(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		if u == nil {
+			changes.RemovedIDs = append(changes.RemovedIDs, id)
+			continue
+		}
+		changes.Changed = append(changes.Changed, *u)
+	}
+
+	return changes, deltaLink, nil
+}
+
+func (a *Adapter) Close() error {
+	a.client.CloseIdleConnections()
+	return nil
+}
+
+// get fetches link and decodes the JSON body into out. The HTTP status is
+// returned alongside any error so callers can react to 404 and 410.
+func (a *Adapter) get(ctx context.Context, link string, out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		// A token request rejected by Entra ID means wrong client
+		// credentials or tenant.
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil && retrieveErr.Response.StatusCode < 500 {
+			return 0, models.Permanent(err)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return resp.StatusCode, models.Permanent(err)
+		}
+		return resp.StatusCode, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+	}
+
+	return resp.StatusCode, nil
+}
+
+type userPage struct {
+	Value     []user `json:"value"`
+	NextLink  string `json:"@odata.nextLink"`
+	DeltaLink string `json:"@odata.deltaLink"`
+}
+
+type user struct {
+	ID                string   `json:"id"`
+	UserPrincipalName string   `json:"userPrincipalName"`
+	Mail              string   `json:"mail"`
+	DisplayName       string   `json:"displayName"`
+	GivenName         string   `json:"givenName"`
+	Surname           string   `json:"surname"`
+	BusinessPhones    []string `json:"businessPhones"`
+	MobilePhone       string   `json:"mobilePhone"`
+	Department        string   `json:"department"`
+	JobTitle          string   `json:"jobTitle"`
+	EmployeeID        string   `json:"employeeId"`
+	OfficeLocation    string   `json:"officeLocation"`
+	AccountEnabled    *bool    `json:"accountEnabled"`
+
+	Removed *struct {
+		Reason string `json:"reason"`
+	} `json:"@removed"`
+}
+
+func (u user) toUser() models.User {
+	email := u.Mail
+	if email == "" {
+		email = u.UserPrincipalName
+	}
+
+	phone := u.MobilePhone
+	if len(u.BusinessPhones) > 0 {
+		phone = u.BusinessPhones[0]
+	}
+
+	return models.User{
+		Status:  toStatus(u.AccountEnabled),
+		IdpType: models.IdentityProviderTypeEntraID,
+		PII: &models.UserPII{
+			SourceID:    u.ID,
+			Username:    u.UserPrincipalName,
+			Email:       email,
+			DisplayName: u.DisplayName,
+			FirstName:   u.GivenName,
+			LastName:    u.Surname,
+			Phone:       phone,
+			Department:  u.Department,
+			Title:       u.JobTitle,
+			EmployeeID:  u.EmployeeID,
+			Location:    u.OfficeLocation,
+		},
+	}
+}
+
+func toStatus(accountEnabled *bool) models.UserStatus {
+	switch {
+	case accountEnabled == nil:
+		return models.UserStatusUnspecified
+	case *accountEnabled:
+		return models.UserStatusActive
+	default:
+		return models.UserStatusDisabled
+	}
+}
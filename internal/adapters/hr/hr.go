@@ -0,0 +1,304 @@
+package hr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+)
+
+const requestTimeout = 60 * time.Second
+
+// maxPages bounds how many pages ListUsers requests, in case a feed keeps
+// answering without ever coming back short.
+const maxPages = 10000
+
+// Adapter reads workers from an HR system report exposed as paginated JSON.
+// HR systems rarely offer a per-worker endpoint, so GetUser reads the whole
+// feed.
+type Adapter struct {
+	cfg    config.IDPConfig
+	client *http.Client
+}
+
+func New(cfg config.IDPConfig) (*Adapter, error) {
+	if _, err := url.Parse(cfg.HR.URL); err != nil {
+		return nil, fmt.Errorf("invalid hr url: %w", err)
+	}
+
+	return &Adapter{
+		cfg:    cfg,
+		client: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+func (a *Adapter) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	users, err := a.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if u.PII.SourceID == userID {
+			return &u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ListUsers reads every page of the feed. Pages are followed through
+// NextField when configured, otherwise requested by number until one comes
+// back short. Either way paging stops at a page adding no new workers, as
+// reports that ignore the paging parameters return the whole feed each time.
+func (a *Adapter) ListUsers(ctx context.Context) ([]models.User, error) {
+	hr := a.cfg.HR
+
+	var users []models.User
+	seen := make(map[string]struct{})
+
+	link := hr.URL
+	for page := 1; link != ""; page++ {
+		if page > maxPages {
+			return nil, fmt.Errorf("list workers: feed did not end after %d pages", maxPages)
+		}
+
+		target := link
+		if hr.NextField == "" && hr.PageParam != "" {
+			var err error
+			if target, err = withPage(hr.URL, hr.PageParam, page, hr.PageSizeParam, hr.PageSize); err != nil {
+				return nil, err
+			}
+		}
+
+		records, next, err := a.readPage(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("list workers: %w", err)
+		}
+
+		added := 0
+		for _, record := range records {
+			u := a.toUser(record)
+			if u.PII.SourceID == "" {
+				return nil, fmt.Errorf("list workers: record without %s", a.key("source_id"))
+			}
+			// Effective-dated reports may list a worker more than once; the
+			// first row wins.
+			if _, ok := seen[u.PII.SourceID]; ok {
+				continue
+			}
+			seen[u.PII.SourceID] = struct{}{}
+			users = append(users, u)
+			added++
+		}
+
+		switch {
+		case added == 0:
+			link = ""
+		case hr.NextField != "":
+			link = next
+		case hr.PageParam == "" || len(records) < hr.PageSize:
+			link = ""
+		}
+	}
+
+	return users, nil
+}
+
+func (a *Adapter) Close() error {
+	a.client.CloseIdleConnections()
+	return nil
+}
+
+// readPage fetches one page and returns its worker records and the next page
+// URL, resolved against target.
+func (a *Adapter) readPage(ctx context.Context, target string) ([]map[string]any, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	switch {
+	case a.cfg.HR.Token != "":
+		req.Header.Set("Authorization", "Bearer "+a.cfg.HR.Token)
+	case a.cfg.HR.Username != "":
+		req.SetBasicAuth(a.cfg.HR.Username, a.cfg.HR.Password)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, "", models.Permanent(err)
+		}
+		return nil, "", err
+	}
+
+	var body any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, "", fmt.Errorf("decode response: %w", err)
+	}
+
+	items := body
+	var next string
+	if a.cfg.HR.RecordsField != "" {
+		doc, ok := body.(map[string]any)
+		if !ok {
+			return nil, "", fmt.Errorf("response is not an object")
+		}
+		items = doc[a.cfg.HR.RecordsField]
+		if a.cfg.HR.NextField != "" {
+			next = stringValue(lookup(doc, a.cfg.HR.NextField))
+		}
+	}
+
+	list, ok := items.([]any)
+	if !ok && items != nil {
+		return nil, "", fmt.Errorf("worker records are not an array")
+	}
+
+	records := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if record, ok := item.(map[string]any); ok {
+			records = append(records, record)
+		}
+	}
+
+	if next != "" {
+		base, _ := url.Parse(target)
+		ref, err := url.Parse(next)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid next page url: %w", err)
+		}
+		next = base.ResolveReference(ref).String()
+	}
+
+	return records, next, nil
+}
+
+// key returns the worker key the field is read from.
+func (a *Adapter) key(field string) string {
+	if key, ok := a.cfg.HR.Mapping[field]; ok {
+		return key
+	}
+	return field
+}
+
+func (a *Adapter) toUser(record map[string]any) models.User {
+	get := func(field string) string {
+		return strings.TrimSpace(stringValue(lookup(record, a.key(field))))
+	}
+
+	return models.User{
+		Status:  a.toStatus(get("status")),
+		IdpType: models.IdentityProviderTypeHR,
+		PII: &models.UserPII{
+			SourceID:    get("source_id"),
+			Username:    get("username"),
+			Email:       get("email"),
+			DisplayName: get("display_name"),
+			FirstName:   get("first_name"),
+			LastName:    get("last_name"),
+			Phone:       get("phone"),
+			Department:  get("department"),
+			Title:       get("title"),
+			ManagerID:   get("manager_id"),
+			EmployeeID:  get("employee_id"),
+			Location:    get("location"),
+		},
+	}
+}
+
+// toStatus maps an employment state through the configured status map.
+// Workers without a state are treated as active; unknown states are left
+// unspecified rather than guessed.
+func (a *Adapter) toStatus(state string) models.UserStatus {
+	if state == "" {
+		return models.UserStatusActive
+	}
+
+	return statuses[a.cfg.HR.StatusMap[strings.ToLower(state)]]
+}
+
+// statuses maps config.UserStatuses to their models counterparts.
+var statuses = map[string]models.UserStatus{
+	"active":           models.UserStatusActive,
+	"disabled":         models.UserStatusDisabled,
+	"locked":           models.UserStatusLocked,
+	"expired":          models.UserStatusExpired,
+	"password_expired": models.UserStatusPasswordExpired,
+	"pending":          models.UserStatusPending,
+	"deleted":          models.UserStatusDeleted,
+}
+
+func withPage(rawURL, pageParam string, page int, sizeParam string, size int) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set(pageParam, strconv.Itoa(page))
+	if sizeParam != "" {
+		query.Set(sizeParam, strconv.Itoa(size))
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// lookup resolves a dotted key. Arrays along the way, common in Workday
+// reports for multi-instance fields, resolve to their first element.
+func lookup(record map[string]any, key string) any {
+	var value any = record
+	for _, part := range strings.Split(key, ".") {
+		value = first(value)
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+	return first(value)
+}
+
+func first(value any) any {
+	if list, ok := value.([]any); ok {
+		if len(list) == 0 {
+			return nil
+		}
+		return list[0]
+	}
+	return value
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,187 @@
+package hr_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"desa-agent/internal/adapters/hr"
+	"desa-agent/internal/adapters/hr/hrtest"
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+)
+
+func newAdapter(t *testing.T, hrCfg config.HRConfig) *hr.Adapter {
+	t.Helper()
+
+	adapter, err := hr.New(config.IDPConfig{HR: hrCfg})
+	if err != nil {
+		t.Fatalf("hr.New: %v", err)
+	}
+	t.Cleanup(func() { _ = adapter.Close() })
+	return adapter
+}
+
+// countingServer serves handler and counts the requests it receives.
+func countingServer(t *testing.T, handler http.Handler) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func sourceIDs(users []models.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.PII.SourceID)
+	}
+	return ids
+}
+
+func TestListUsersPaging(t *testing.T) {
+	server, requests := countingServer(t, hrtest.Handler())
+	adapter := newAdapter(t, hrtest.Config(server.URL))
+
+	users, err := adapter.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+
+	want := []string{"10001", "10002", "10003", "10004", "10005"}
+	if got := sourceIDs(users); !slices.Equal(got, want) {
+		t.Errorf("source ids = %v, want %v", got, want)
+	}
+	// Five workers at two per page end with a short third page.
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestListUsersFeedIgnoringPaging(t *testing.T) {
+	// Like Workday RaaS, answer every page with the whole feed. Its size is
+	// a multiple of the page size, so no page ever comes back short.
+	server, requests := countingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.RawQuery = ""
+		hrtest.Handler().ServeHTTP(w, r)
+	}))
+	hrCfg := hrtest.Config(server.URL)
+	hrCfg.PageSize = len(hrtest.Workers())
+	adapter := newAdapter(t, hrCfg)
+
+	users, err := adapter.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+
+	if len(users) != len(hrtest.Workers()) {
+		t.Errorf("users = %d, want %d", len(users), len(hrtest.Workers()))
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestListUsersDuplicateRows(t *testing.T) {
+	workers := hrtest.Workers()
+	later := map[string]any{
+		"Employee_ID":   workers[0]["Employee_ID"],
+		"Worker":        "Alice Johnson",
+		"Email_Address": "alice@example.com",
+		"Worker_Status": "Terminated",
+	}
+	workers = append(workers, later)
+
+	server, _ := countingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"Report_Entry": workers})
+	}))
+	hrCfg := hrtest.Config(server.URL)
+	hrCfg.Token = ""
+	hrCfg.PageParam = ""
+	adapter := newAdapter(t, hrCfg)
+
+	users, err := adapter.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+
+	if len(users) != len(workers)-1 {
+		t.Fatalf("users = %d, want %d", len(users), len(workers)-1)
+	}
+	if got := users[0].PII.Email; got != "alice.johnson@example.com" {
+		t.Errorf("email = %q, want the first row's", got)
+	}
+	if got := users[0].Status; got != models.UserStatusActive {
+		t.Errorf("status = %v, want the first row's", got)
+	}
+}
+
+func TestListUsersFieldMapping(t *testing.T) {
+	server := hrtest.NewServer()
+	t.Cleanup(server.Close)
+	adapter := newAdapter(t, hrtest.Config(server.URL))
+
+	users, err := adapter.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+
+	byID := make(map[string]models.User, len(users))
+	for _, u := range users {
+		byID[u.PII.SourceID] = u
+	}
+
+	bob := byID["10002"]
+	if bob.IdpType != models.IdentityProviderTypeHR {
+		t.Errorf("idp type = %v, want hr", bob.IdpType)
+	}
+	pii := bob.PII
+	for _, tc := range []struct{ field, got, want string }{
+		{"employee_id", pii.EmployeeID, "10002"},
+		{"email", pii.Email, "bob.smith@example.com"},
+		{"display_name", pii.DisplayName, "Bob Smith"},
+		{"first_name", pii.FirstName, "Bob"},
+		{"last_name", pii.LastName, "Smith"},
+		{"department", pii.Department, "Engineering"},
+		{"title", pii.Title, "Software Engineer"},
+		{"manager_id", pii.ManagerID, "10001"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.field, tc.got, tc.want)
+		}
+	}
+
+	statuses := map[string]models.UserStatus{
+		"10001": models.UserStatusActive,
+		"10003": models.UserStatusDisabled,
+		"10004": models.UserStatusDisabled,
+		"10005": models.UserStatusPending,
+	}
+	for id, want := range statuses {
+		if got := byID[id].Status; got != want {
+			t.Errorf("status of %s = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestListUsersUnauthorized(t *testing.T) {
+	server := hrtest.NewServer()
+	t.Cleanup(server.Close)
+	hrCfg := hrtest.Config(server.URL)
+	hrCfg.Token = "wrong"
+	adapter := newAdapter(t, hrCfg)
+
+	_, err := adapter.ListUsers(context.Background())
+	if !errors.Is(err, models.ErrPermanent) {
+		t.Errorf("err = %v, want a permanent error", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package hrtest serves a fixture worker feed in the Workday RaaS format, so
+// the hr adapter can be exercised without an HR system.
+package hrtest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"desa-agent/internal/config"
+)
+
+// Token is the bearer token the fixture server accepts.
+const Token = "hrtest-token"
+
+//go:embed testdata/workers.json
+var workersJSON []byte
+
+// Workers returns the fixture workers.
+func Workers() []map[string]any {
+	var workers []map[string]any
+	if err := json.Unmarshal(workersJSON, &workers); err != nil {
+		panic("hrtest: invalid fixture: " + err.Error())
+	}
+	return workers
+}
+
+// Handler serves Workers as {"Report_Entry": [...]}, paged by the "page"
+// (1-based) and "limit" query parameters.
+func Handler() http.Handler {
+	workers := Workers()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		page := queryInt(r, "page", 1)
+		limit := queryInt(r, "limit", len(workers))
+		if page < 1 || limit < 1 {
+			http.Error(w, "invalid paging", http.StatusBadRequest)
+			return
+		}
+
+		start := min((page-1)*limit, len(workers))
+		end := min(start+limit, len(workers))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"Report_Entry": workers[start:end]})
+	})
+}
+
+// NewServer starts a server for Handler. The caller must Close it.
+func NewServer() *httptest.Server {
+	return httptest.NewServer(Handler())
+}
+
+// Config returns an hr configuration reading the fixture from serverURL.
+func Config(serverURL string) config.HRConfig {
+	return config.HRConfig{
+		URL:           serverURL,
+		Token:         Token,
+		RecordsField:  "Report_Entry",
+		PageParam:     "page",
+		PageSizeParam: "limit",
+		PageSize:      2,
+		Mapping: map[string]string{
+			"source_id":    "Employee_ID",
+			"employee_id":  "Employee_ID",
+			"email":        "Email_Address",
+			"display_name": "Worker",
+			"first_name":   "Legal_First_Name",
+			"last_name":    "Legal_Last_Name",
+			"department":   "Supervisory_Organization",
+			"title":        "Business_Title",
+			"manager_id":   "Manager.Employee_ID",
+			"status":       "Worker_Status",
+		},
+		StatusMap: map[string]string{
+			"active":     "active",
+			"on leave":   "disabled",
+			"terminated": "disabled",
+			"pre-hire":   "pending",
+		},
+	}
+}
+
+func queryInt(r *http.Request, name string, fallback int) int {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return n
+}
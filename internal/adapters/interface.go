@@ -11,3 +11,9 @@ type IdentityProvider interface {
 	ListUsers(ctx context.Context) ([]models.User, error)
 	Close() error
 }
+
+// Verifier is implemented by identity providers that can check their
+// connection settings and credentials without reading users.
+type Verifier interface {
+	Verify(ctx context.Context) error
+}
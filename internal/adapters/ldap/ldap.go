@@ -2,26 +2,202 @@ package ldap
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+
+	"desa-agent/internal/adapters/directory"
 	"desa-agent/internal/config"
 	"desa-agent/internal/models"
 )
 
+const defaultUserFilter = "(objectClass=inetOrgPerson)"
+
+// permanentLock is the pwdAccountLockedTime value of accounts locked by an
+// administrator rather than by failed binds.
+const permanentLock = "000001010000Z"
+
+var userAttributes = []string{
+	"entryUUID",
+	"uid",
+	"mail",
+	"displayName",
+	"cn",
+	"givenName",
+	"sn",
+	"telephoneNumber",
+	"mobile",
+	"departmentNumber",
+	"ou",
+	"title",
+	"manager",
+	"employeeNumber",
+	"l",
+	"createTimestamp",
+	"pwdChangedTime",
+	"pwdLastSuccess",
+	"authTimestamp",
+	"pwdAccountLockedTime",
+	"pwdReset",
+	"pwdStartTime",
+	"pwdEndTime",
+	"shadowExpire",
+	"shadowLastChange",
+	"shadowMax",
+}
+
 type Adapter struct {
-	cfg config.IDPConfig
+	cfg    config.IDPConfig
+	client *directory.Client
 }
 
 func New(cfg config.IDPConfig) (*Adapter, error) {
-	return &Adapter{cfg: cfg}, nil
+	client, err := directory.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Adapter{cfg: cfg, client: client}, nil
 }
 
 func (a *Adapter) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
+	filter := directory.And(
+		a.client.Filter(defaultUserFilter),
+		"(entryUUID="+goldap.EscapeFilter(userID)+")",
+	)
+
+	entries, err := a.client.Search(ctx, filter, userAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("search user %s: %w", userID, err)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	user := toUser(entries[0], time.Now())
+	return &user, nil
 }
 
 func (a *Adapter) ListUsers(ctx context.Context) ([]models.User, error) {
-	return nil, nil
+	entries, err := a.client.Search(ctx, a.client.Filter(defaultUserFilter), userAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+
+	now := time.Now()
+	users := make([]models.User, 0, len(entries))
+	for _, entry := range entries {
+		users = append(users, toUser(entry, now))
+	}
+
+	return users, nil
+}
+
+// Verify checks that the directory accepts the configured credentials.
+func (a *Adapter) Verify(ctx context.Context) error {
+	return a.client.Verify(ctx)
 }
 
 func (a *Adapter) Close() error {
+	a.client.Close()
+	return nil
+}
+
+func toUser(entry *goldap.Entry, now time.Time) models.User {
+	return models.User{
+		Status:  toStatus(entry, now),
+		IdpType: models.IdentityProviderTypeLDAP,
+		PII: &models.UserPII{
+			SourceID:    entry.GetAttributeValue("entryUUID"),
+			Username:    entry.GetAttributeValue("uid"),
+			Email:       entry.GetAttributeValue("mail"),
+			DisplayName: firstNonEmpty(entry.GetAttributeValue("displayName"), entry.GetAttributeValue("cn")),
+			FirstName:   entry.GetAttributeValue("givenName"),
+			LastName:    entry.GetAttributeValue("sn"),
+			Phone:       firstNonEmpty(entry.GetAttributeValue("telephoneNumber"), entry.GetAttributeValue("mobile")),
+			Department:  firstNonEmpty(entry.GetAttributeValue("departmentNumber"), entry.GetAttributeValue("ou")),
+			Title:       entry.GetAttributeValue("title"),
+			ManagerID:   entry.GetAttributeValue("manager"),
+			EmployeeID:  entry.GetAttributeValue("employeeNumber"),
+			Location:    entry.GetAttributeValue("l"),
+			Attributes: []models.Attribute{
+				{Key: models.AttributeKeyDistinguishedName, Value: entry.DN},
+			},
+		},
+		// pwdLastSuccess is kept by the ppolicy overlay, authTimestamp by the
+		// older lastbind overlay.
+		LastLogonAt:       timeAttribute(entry, "pwdLastSuccess", "authTimestamp"),
+		PasswordLastSetAt: timeAttribute(entry, "pwdChangedTime"),
+		CreatedAt:         timeAttribute(entry, "createTimestamp"),
+	}
+}
+
+// toStatus derives the account state from the password policy overlay
+// (pwdAccountLockedTime, pwdReset, pwdStartTime, pwdEndTime) and the
+// shadowAccount attributes. Entries without either are active.
+func toStatus(entry *goldap.Entry, now time.Time) models.UserStatus {
+	lockedTime := entry.GetAttributeValue("pwdAccountLockedTime")
+	if lockedTime == permanentLock {
+		return models.UserStatusDisabled
+	}
+
+	if end, ok := directory.GeneralizedTime(entry, "pwdEndTime"); ok && end.Before(now) {
+		return models.UserStatusExpired
+	}
+
+	// shadowExpire and shadowLastChange count days since the epoch.
+	today := now.Unix() / 86400
+	if expire, ok := intAttribute(entry, "shadowExpire"); ok && expire >= 0 && expire <= today {
+		return models.UserStatusExpired
+	}
+
+	if start, ok := directory.GeneralizedTime(entry, "pwdStartTime"); ok && start.After(now) {
+		return models.UserStatusPending
+	}
+
+	if lockedTime != "" {
+		return models.UserStatusLocked
+	}
+
+	if entry.GetAttributeValue("pwdReset") == "TRUE" {
+		return models.UserStatusPasswordExpired
+	}
+
+	if lastChange, ok := intAttribute(entry, "shadowLastChange"); ok {
+		if lastChange == 0 {
+			return models.UserStatusPasswordExpired
+		}
+		if maxAge, ok := intAttribute(entry, "shadowMax"); ok && maxAge >= 0 && lastChange+maxAge < today {
+			return models.UserStatusPasswordExpired
+		}
+	}
+
+	return models.UserStatusActive
+}
+
+// timeAttribute returns the first of attrs holding a valid GeneralizedTime.
+func timeAttribute(entry *goldap.Entry, attrs ...string) *time.Time {
+	for _, attr := range attrs {
+		if t, ok := directory.GeneralizedTime(entry, attr); ok {
+			return &t
+		}
+	}
 	return nil
 }
+
+func intAttribute(entry *goldap.Entry, attr string) (int64, bool) {
+	n, err := strconv.ParseInt(entry.GetAttributeValue(attr), 10, 64)
+	return n, err == nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
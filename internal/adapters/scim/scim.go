@@ -0,0 +1,143 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+	schema "desa-agent/internal/scim"
+)
+
+const requestTimeout = 30 * time.Second
+
+// maxPages bounds how many pages ListUsers requests.
+const maxPages = 10000
+
+// Adapter reads users from a SCIM 2.0 service provider such as Entra ID,
+// Okta or JumpCloud.
+type Adapter struct {
+	cfg     config.IDPConfig
+	baseURL string
+	client  *http.Client
+}
+
+func New(cfg config.IDPConfig) (*Adapter, error) {
+	return &Adapter{
+		cfg:     cfg,
+		baseURL: strings.TrimRight(cfg.SCIM.BaseURL, "/"),
+		client:  &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+func (a *Adapter) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	var resource schema.User
+	found, err := a.get(ctx, "/Users/"+url.PathEscape(userID), nil, &resource)
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", userID, err)
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	user := schema.ToUser(resource)
+	return &user, nil
+}
+
+// ListUsers pages through /Users using 1-based startIndex pagination.
+// Providers are known to misreport totalResults, so passing it only ends the
+// listing together with a short page. A page adding no new users always
+// does, which also guards against providers ignoring startIndex.
+func (a *Adapter) ListUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	seen := make(map[string]struct{})
+
+	startIndex := 1
+	for pages := 1; ; pages++ {
+		if pages > maxPages {
+			return nil, fmt.Errorf("list users: no end after %d pages", maxPages)
+		}
+
+		query := url.Values{
+			"startIndex": {strconv.Itoa(startIndex)},
+			"count":      {strconv.Itoa(a.cfg.SCIM.PageSize)},
+		}
+
+		var page schema.ListResponse[schema.User]
+		if _, err := a.get(ctx, "/Users", query, &page); err != nil {
+			return nil, fmt.Errorf("list users at index %d: %w", startIndex, err)
+		}
+
+		added := 0
+		for _, resource := range page.Resources {
+			if _, ok := seen[resource.ID]; ok {
+				continue
+			}
+			seen[resource.ID] = struct{}{}
+			users = append(users, schema.ToUser(resource))
+			added++
+		}
+
+		startIndex += len(page.Resources)
+		short := len(page.Resources) < a.cfg.SCIM.PageSize
+		if added == 0 || (short && startIndex > page.TotalResults) {
+			break
+		}
+	}
+
+	return users, nil
+}
+
+func (a *Adapter) Close() error {
+	a.client.CloseIdleConnections()
+	return nil
+}
+
+// get fetches path and decodes the JSON body into out. It reports false
+// without an error when the resource does not exist.
+func (a *Adapter) get(ctx context.Context, path string, query url.Values, out any) (bool, error) {
+	target := a.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.cfg.SCIM.Token)
+	req.Header.Set("Accept", schema.ContentType+", application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return false, models.Permanent(err)
+		}
+		return false, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decode response: %w", err)
+	}
+
+	return true, nil
+}
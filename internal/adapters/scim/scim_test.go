@@ -0,0 +1,293 @@
+package scim_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"desa-agent/internal/adapters/scim"
+	"desa-agent/internal/config"
+	"desa-agent/internal/models"
+	schema "desa-agent/internal/scim"
+)
+
+const token = "scim-test-token"
+
+// provider is a SCIM service provider stand-in serving users from /Users.
+type provider struct {
+	users []schema.User
+	// maxItems caps the page size regardless of the requested count.
+	maxItems int
+	// total overrides the reported totalResults when set.
+	total func(actual int) int
+
+	mu     sync.Mutex
+	starts []int
+}
+
+func newProvider(n int) *provider {
+	p := &provider{}
+	for i := 1; i <= n; i++ {
+		p.users = append(p.users, schema.User{
+			Schemas:  []string{schema.SchemaUser},
+			ID:       strconv.Itoa(i),
+			UserName: fmt.Sprintf("user%d@example.com", i),
+		})
+	}
+	return p
+}
+
+func (p *provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if id, ok := strings.CutPrefix(r.URL.Path, "/Users/"); ok {
+		for _, u := range p.users {
+			if u.ID == id {
+				writeJSON(w, u)
+				return
+			}
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+	if p.maxItems > 0 {
+		count = min(count, p.maxItems)
+	}
+
+	p.mu.Lock()
+	p.starts = append(p.starts, startIndex)
+	p.mu.Unlock()
+
+	start := min(max(startIndex, 1)-1, len(p.users))
+	end := min(start+count, len(p.users))
+
+	total := len(p.users)
+	if p.total != nil {
+		total = p.total(total)
+	}
+
+	writeJSON(w, schema.ListResponse[schema.User]{
+		Schemas:      []string{schema.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: end - start,
+		Resources:    p.users[start:end],
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", schema.ContentType)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newAdapter(t *testing.T, handler http.Handler, pageSize int) *scim.Adapter {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	adapter, err := scim.New(config.IDPConfig{
+		SCIM: config.SCIMConfig{
+			BaseURL:  server.URL + "/",
+			Token:    token,
+			PageSize: pageSize,
+		},
+	})
+	if err != nil {
+		t.Fatalf("scim.New: %v", err)
+	}
+	t.Cleanup(func() { _ = adapter.Close() })
+	return adapter
+}
+
+func sourceIDs(users []models.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.PII.SourceID)
+	}
+	return ids
+}
+
+func TestListUsersPaging(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		maxItems int
+		total    func(int) int
+		starts   []int
+	}{
+		{
+			name:   "exact total",
+			starts: []int{1, 3, 5},
+		},
+		{
+			name:     "provider caps page size",
+			maxItems: 1,
+			starts:   []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:   "short total",
+			total:  func(int) int { return 2 },
+			starts: []int{1, 3, 5},
+		},
+		{
+			name:   "missing total",
+			total:  func(int) int { return 0 },
+			starts: []int{1, 3, 5},
+		},
+		{
+			name:   "overstated total",
+			total:  func(n int) int { return n * 2 },
+			starts: []int{1, 3, 5, 6},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newProvider(5)
+			p.maxItems, p.total = tc.maxItems, tc.total
+			adapter := newAdapter(t, p, 2)
+
+			users, err := adapter.ListUsers(context.Background())
+			if err != nil {
+				t.Fatalf("ListUsers: %v", err)
+			}
+
+			want := []string{"1", "2", "3", "4", "5"}
+			if got := sourceIDs(users); !slices.Equal(got, want) {
+				t.Errorf("source ids = %v, want %v", got, want)
+			}
+			if !slices.Equal(p.starts, tc.starts) {
+				t.Errorf("start indexes = %v, want %v", p.starts, tc.starts)
+			}
+		})
+	}
+}
+
+func TestListUsersIgnoredStartIndex(t *testing.T) {
+	p := newProvider(4)
+	adapter := newAdapter(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		query.Set("startIndex", "1")
+		r.URL.RawQuery = query.Encode()
+		p.ServeHTTP(w, r)
+	}), 2)
+
+	users, err := adapter.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+
+	if got, want := sourceIDs(users), []string{"1", "2"}; !slices.Equal(got, want) {
+		t.Errorf("source ids = %v, want %v", got, want)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	var authorization string
+	p := newProvider(1)
+	adapter := newAdapter(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		p.ServeHTTP(w, r)
+	}), 10)
+
+	if _, err := adapter.ListUsers(context.Background()); err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if want := "Bearer " + token; authorization != want {
+		t.Errorf("Authorization = %q, want %q", authorization, want)
+	}
+}
+
+func TestErrorStatuses(t *testing.T) {
+	for _, tc := range []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+	} {
+		t.Run(strconv.Itoa(tc.status), func(t *testing.T) {
+			adapter := newAdapter(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, http.StatusText(tc.status), tc.status)
+			}), 10)
+
+			_, err := adapter.ListUsers(context.Background())
+			if err == nil {
+				t.Fatal("ListUsers succeeded")
+			}
+			if got := errors.Is(err, models.ErrPermanent); got != tc.permanent {
+				t.Errorf("permanent = %v, want %v: %v", got, tc.permanent, err)
+			}
+		})
+	}
+}
+
+func TestGetUserEnterpriseExtension(t *testing.T) {
+	active := false
+	p := newProvider(0)
+	p.users = []schema.User{{
+		Schemas:     []string{schema.SchemaUser, schema.SchemaEnterpriseUser},
+		ID:          "2819c223",
+		UserName:    "bjensen@example.com",
+		DisplayName: "Babs Jensen",
+		Title:       "Tour Guide",
+		Active:      &active,
+		Name:        &schema.Name{GivenName: "Barbara", FamilyName: "Jensen"},
+		Emails: []schema.MultiValue{
+			{Value: "babs@jensen.org", Type: "home"},
+			{Value: "bjensen@example.com", Type: "work", Primary: true},
+		},
+		Enterprise: &schema.EnterpriseUser{
+			EmployeeNumber: "701984",
+			Department:     "Tour Operations",
+			Manager:        &schema.Manager{Value: "26118915"},
+		},
+	}}
+	adapter := newAdapter(t, p, 10)
+
+	user, err := adapter.GetUser(context.Background(), "2819c223")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user == nil {
+		t.Fatal("GetUser found no user")
+	}
+
+	if user.Status != models.UserStatusDisabled {
+		t.Errorf("status = %v, want disabled", user.Status)
+	}
+	pii := user.PII
+	for _, tc := range []struct{ field, got, want string }{
+		{"source_id", pii.SourceID, "2819c223"},
+		{"username", pii.Username, "bjensen@example.com"},
+		{"email", pii.Email, "bjensen@example.com"},
+		{"first_name", pii.FirstName, "Barbara"},
+		{"last_name", pii.LastName, "Jensen"},
+		{"employee_id", pii.EmployeeID, "701984"},
+		{"department", pii.Department, "Tour Operations"},
+		{"manager_id", pii.ManagerID, "26118915"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.field, tc.got, tc.want)
+		}
+	}
+
+	missing, err := adapter.GetUser(context.Background(), "unknown")
+	if err != nil || missing != nil {
+		t.Errorf("GetUser of an unknown user = %v, %v, want nil, nil", missing, err)
+	}
+}
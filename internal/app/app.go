@@ -2,29 +2,46 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+
 	"desa-agent/internal/adapters"
 	"desa-agent/internal/config"
+	"desa-agent/internal/encryption"
+	"desa-agent/internal/models"
 	"desa-agent/internal/storage"
 	"desa-agent/internal/transport"
+	scimtransport "desa-agent/internal/transport/scim"
 	"desa-agent/internal/usecase"
+	pb "desa-agent/pkg/users"
 )
 
+// verifyTimeout bounds the startup credential check of each identity
+// provider.
+const verifyTimeout = 30 * time.Second
+
 type App struct {
-	cfg        *config.Config
-	grpcServer *grpc.Server
-	storage    *storage.Storage
-	idp        adapters.IdentityProvider
-	usersUC    *usecase.UsersUseCase
-	logger     *slog.Logger
+	cfg          *config.Config
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	scimServer   *http.Server
+	storage      *storage.Storage
+	idps         []adapters.IdentityProvider
+	usersUC      *usecase.UsersUseCase
+	logger       *slog.Logger
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -32,9 +49,17 @@ func New(cfg *config.Config) (*App, error) {
 		Level: slog.LevelInfo,
 	}))
 
+	return newApp(cfg, logger)
+}
+
+func newApp(cfg *config.Config, logger *slog.Logger) (*App, error) {
 	store, err := storage.New(storage.Config{
 		Path:     cfg.Storage.Path,
 		InMemory: cfg.Storage.InMemory,
+		ReadOnly: cfg.Storage.ReadOnly,
+
+		SyncRunRetention:     cfg.Storage.SyncRunRetention,
+		UserHistoryRetention: cfg.Storage.UserHistoryRetention,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create storage: %w", err)
@@ -43,35 +68,141 @@ func New(cfg *config.Config) (*App, error) {
 	logger.Info("storage created",
 		"path", cfg.Storage.Path,
 		"in_memory", cfg.Storage.InMemory,
+		"user_history", cfg.Storage.UserHistoryKey != "",
 	)
 
-	idp, err := adapters.NewIdentityProvider(cfg.IDP)
+	historyKey, err := cfg.Storage.HistoryKey()
 	if err != nil {
 		store.Close()
-		return nil, fmt.Errorf("failed to create identity provider: %w", err)
+		return nil, err
 	}
 
-	logger.Info("identity provider adapter created",
-		"type", cfg.IDP.Type,
-		"host", cfg.IDP.Host,
-	)
+	history := usecase.UserHistory{Retention: cfg.Storage.UserHistoryRetention}
+	if historyKey != nil {
+		history.Cipher, err = encryption.NewCipher(historyKey)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to create user history cipher: %w", err)
+		}
 
-	usersUC := usecase.NewUsersUseCase(store, idp)
+		if !cfg.Storage.ReadOnly {
+			history.Since, err = store.UserHistorySince(context.Background(), time.Now().UTC())
+			if err != nil {
+				store.Close()
+				return nil, fmt.Errorf("failed to read user history start: %w", err)
+			}
+		}
+	} else if !cfg.Storage.ReadOnly {
+		// Changes made while history is off are not recorded, so it must
+		// start anew when enabled again.
+		if err := store.ClearUserHistorySince(context.Background()); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+
+	idps := make([]adapters.IdentityProvider, 0, len(cfg.IDPs))
+	sources := make([]usecase.Source, 0, len(cfg.IDPs))
+	for _, idpCfg := range cfg.IDPs {
+		idp, err := adapters.NewIdentityProvider(idpCfg)
+		if err != nil {
+			closeIdentityProviders(idps, logger)
+			store.Close()
+			return nil, fmt.Errorf("failed to create identity provider %s: %w", idpCfg.Name, err)
+		}
+
+		if verifier, ok := idp.(adapters.Verifier); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+			err := verifier.Verify(ctx)
+			cancel()
+			// Rejected credentials and other permanent failures always
+			// refuse to start; transient ones follow the stale policy,
+			// the others keeping the source and letting its syncs retry.
+			if err != nil && (errors.Is(err, models.ErrPermanent) || cfg.Startup.StalePolicy == config.StalePolicyFail) {
+				idp.Close()
+				closeIdentityProviders(idps, logger)
+				store.Close()
+				return nil, fmt.Errorf("identity provider %s failed the startup connection and credentials check: %w", idpCfg.Name, err)
+			}
+			if err != nil {
+				logger.Error("identity provider failed the startup connection and credentials check",
+					"source", idpCfg.Name,
+					"error", err,
+				)
+			}
+		}
+
+		logger.Info("identity provider adapter created",
+			"source", idpCfg.Name,
+			"type", idpCfg.Type,
+			"host", idpCfg.Host,
+			"servers", idpCfg.Servers,
+			"discovery_domain", idpCfg.DiscoveryDomain,
+			"sync_interval", idpCfg.Schedule.Interval,
+			"sync_cron", idpCfg.Schedule.Cron,
+			"full_sync_interval", idpCfg.Schedule.FullInterval,
+			"full_sync_cron", idpCfg.Schedule.FullCron,
+		)
+
+		schedule, err := newSyncSchedule(idpCfg.Schedule)
+		if err != nil {
+			idp.Close()
+			closeIdentityProviders(idps, logger)
+			store.Close()
+			return nil, fmt.Errorf("invalid sync schedule for identity provider %s: %w", idpCfg.Name, err)
+		}
+
+		idps = append(idps, idp)
+		sources = append(sources, usecase.Source{
+			Name:     idpCfg.Name,
+			IDP:      idp,
+			Schedule: schedule,
+		})
+	}
+
+	classifier, err := usecase.NewClassifier(cfg.Classification.Rules)
+	if err != nil {
+		closeIdentityProviders(idps, logger)
+		store.Close()
+		return nil, fmt.Errorf("failed to create account classifier: %w", err)
+	}
+
+	usersUC := usecase.NewUsersUseCase(store, sources, cfg.Correlation.Keys, classifier, history)
 
 	grpcServer := grpc.NewServer()
 
 	usersService := transport.NewUsersServiceServer(usersUC)
 	usersService.Register(grpcServer)
 
+	adminService := transport.NewAdminServiceServer(usersUC)
+	adminService.Register(grpcServer)
+
+	// Not serving until the startup sync completes, see awaitStartupSync.
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(pb.UsersService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
 	reflection.Register(grpcServer)
 
+	var scimServer *http.Server
+	if cfg.SCIMServer.Enabled {
+		scimServer = &http.Server{
+			Addr:              cfg.SCIMServer.Address(),
+			Handler:           scimtransport.NewServer(usersUC, cfg.SCIMServer.Source, cfg.SCIMServer.Token).Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	}
+
 	return &App{
-		cfg:        cfg,
-		grpcServer: grpcServer,
-		storage:    store,
-		idp:        idp,
-		usersUC:    usersUC,
-		logger:     logger,
+		cfg:          cfg,
+		grpcServer:   grpcServer,
+		healthServer: healthServer,
+		scimServer:   scimServer,
+		storage:      store,
+		idps:         idps,
+		usersUC:      usersUC,
+		logger:       logger,
 	}, nil
 }
 
@@ -95,8 +226,28 @@ func (a *App) Run(ctx context.Context) error {
 		}
 	}()
 
+	if a.scimServer != nil {
+		go func() {
+			a.logger.Info("starting SCIM server",
+				"address", a.scimServer.Addr,
+				"source", a.cfg.SCIMServer.Source,
+			)
+
+			var err error
+			if a.cfg.SCIMServer.TLSCertFile != "" {
+				err = a.scimServer.ListenAndServeTLS(a.cfg.SCIMServer.TLSCertFile, a.cfg.SCIMServer.TLSKeyFile)
+			} else {
+				err = a.scimServer.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- fmt.Errorf("SCIM server error: %w", err)
+			}
+		}()
+	}
+
 	// Start the user sync job
 	go a.usersUC.StartSyncJob(ctx, a.logger)
+	go a.awaitStartupSync(ctx, errCh)
 
 	select {
 	case <-ctx.Done():
@@ -113,12 +264,19 @@ func (a *App) Run(ctx context.Context) error {
 func (a *App) Shutdown() error {
 	a.logger.Info("shutting down application")
 
+	a.healthServer.Shutdown()
 	a.grpcServer.GracefulStop()
 
-	if err := a.idp.Close(); err != nil {
-		a.logger.Error("failed to close identity provider", "error", err)
+	if a.scimServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := a.scimServer.Shutdown(ctx); err != nil {
+			a.logger.Error("failed to shut down SCIM server", "error", err)
+		}
+		cancel()
 	}
 
+	closeIdentityProviders(a.idps, a.logger)
+
 	if err := a.storage.Close(); err != nil {
 		a.logger.Error("failed to close storage", "error", err)
 	}
@@ -126,3 +284,101 @@ func (a *App) Shutdown() error {
 	a.logger.Info("application shutdown complete")
 	return nil
 }
+
+// awaitStartupSync reports the agent as serving once every source completed
+// its first sync. When the startup timeout passes first, the stale policy
+// decides between failing, serving the stored users and waiting longer.
+func (a *App) awaitStartupSync(ctx context.Context, errCh chan<- error) {
+	timer := time.NewTimer(a.cfg.Startup.SyncTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-a.usersUC.Ready():
+		a.logger.Info("startup sync completed, serving")
+		a.setServing()
+		return
+	case <-timer.C:
+	}
+
+	switch a.cfg.Startup.StalePolicy {
+	case config.StalePolicyFail:
+		select {
+		case errCh <- fmt.Errorf("startup sync did not complete within %s", a.cfg.Startup.SyncTimeout):
+		case <-ctx.Done():
+		}
+		return
+	case config.StalePolicyServe:
+		hasUsers, err := a.usersUC.HasUsers(ctx)
+		if err != nil {
+			a.logger.Error("failed to check for stored users", "error", err)
+		}
+		if hasUsers {
+			a.logger.Warn("startup sync did not complete in time, serving stored users",
+				"timeout", a.cfg.Startup.SyncTimeout,
+			)
+			a.setServing()
+			return
+		}
+		a.logger.Warn("startup sync did not complete in time and no users are stored, waiting",
+			"timeout", a.cfg.Startup.SyncTimeout,
+		)
+	default:
+		a.logger.Warn("startup sync did not complete in time, waiting",
+			"timeout", a.cfg.Startup.SyncTimeout,
+		)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-a.usersUC.Ready():
+		a.logger.Info("startup sync completed, serving")
+		a.setServing()
+	}
+}
+
+func (a *App) setServing() {
+	a.healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	a.healthServer.SetServingStatus(pb.UsersService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+}
+
+func closeIdentityProviders(idps []adapters.IdentityProvider, logger *slog.Logger) {
+	for _, idp := range idps {
+		if err := idp.Close(); err != nil {
+			logger.Error("failed to close identity provider", "error", err)
+		}
+	}
+}
+
+// newSyncSchedule turns the configured intervals and cron expressions into
+// the schedule of a source.
+func newSyncSchedule(cfg config.ScheduleConfig) (usecase.SyncSchedule, error) {
+	schedule := usecase.SyncSchedule{
+		Incremental:  usecase.Every(cfg.Interval),
+		Full:         usecase.Every(cfg.FullInterval),
+		Jitter:       cfg.Jitter,
+		QuietWindows: cfg.QuietWindows,
+
+		RetryBackoff:    cfg.RetryBackoff,
+		RetryMaxBackoff: cfg.RetryMaxBackoff,
+	}
+
+	if cfg.Cron != "" {
+		incremental, err := cron.ParseStandard(cfg.Cron)
+		if err != nil {
+			return usecase.SyncSchedule{}, fmt.Errorf("parse sync cron: %w", err)
+		}
+		schedule.Incremental = incremental
+	}
+
+	if cfg.FullCron != "" {
+		full, err := cron.ParseStandard(cfg.FullCron)
+		if err != nil {
+			return usecase.SyncSchedule{}, fmt.Errorf("parse full sync cron: %w", err)
+		}
+		schedule.Full = full
+	}
+
+	return schedule, nil
+}
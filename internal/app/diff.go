@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"desa-agent/internal/config"
+)
+
+// Diff writes to w, as JSON, what a full sync of the named sources, or of
+// every source when none is named, would change in the store. Nothing is
+// written: the store is opened read-only, which requires the agent using it
+// to be stopped. A running agent answers the same through the PreviewSync
+// admin RPC, but only for its own configuration.
+func Diff(ctx context.Context, cfg *config.Config, w io.Writer, sources ...string) error {
+	// Logs go to stderr so that the diff can be piped.
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+	}))
+
+	cfg.Storage.ReadOnly = true
+	a, err := newApp(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeIdentityProviders(a.idps, logger)
+		if err := a.storage.Close(); err != nil {
+			logger.Error("failed to close storage", "error", err)
+		}
+	}()
+
+	if len(sources) == 0 {
+		for _, idpCfg := range cfg.IDPs {
+			sources = append(sources, idpCfg.Name)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	for _, source := range sources {
+		diff, err := a.usersUC.PreviewSync(ctx, source)
+		if err != nil {
+			return fmt.Errorf("failed to preview sync of %s: %w", source, err)
+		}
+		if diff == nil {
+			return fmt.Errorf("unknown source: %s", source)
+		}
+
+		if err := enc.Encode(diff); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	}
+
+	return nil
+}
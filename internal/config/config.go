@@ -1,9 +1,24 @@
 package config
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/robfig/cron/v3"
+
+	"desa-agent/internal/models"
 )
 
 type IdentityProviderType string
@@ -11,12 +26,101 @@ type IdentityProviderType string
 const (
 	IdentityProviderTypeActiveDirectory IdentityProviderType = "active_directory"
 	IdentityProviderTypeLDAP            IdentityProviderType = "ldap"
+	IdentityProviderTypeSCIM            IdentityProviderType = "scim"
+	IdentityProviderTypeEntraID         IdentityProviderType = "entra_id"
+	IdentityProviderTypeGoogleWorkspace IdentityProviderType = "google_workspace"
+	IdentityProviderTypeFile            IdentityProviderType = "file"
+	IdentityProviderTypeHR              IdentityProviderType = "hr"
+)
+
+var identityProviderTypes = []string{
+	string(IdentityProviderTypeActiveDirectory),
+	string(IdentityProviderTypeLDAP),
+	string(IdentityProviderTypeSCIM),
+	string(IdentityProviderTypeEntraID),
+	string(IdentityProviderTypeGoogleWorkspace),
+	string(IdentityProviderTypeFile),
+	string(IdentityProviderTypeHR),
+}
+
+type FileFormat string
+
+const (
+	FileFormatCSV   FileFormat = "csv"
+	FileFormatJSONL FileFormat = "jsonl"
+)
+
+// UserFields lists the user fields a file column or feed key can be mapped to.
+var UserFields = []string{
+	"source_id", "username", "email", "display_name", "first_name", "last_name", "phone",
+	"department", "title", "manager_id", "employee_id", "location", "status",
+}
+
+// UserStatuses lists the statuses an HR employment state can map to.
+var UserStatuses = []string{"active", "disabled", "locked", "expired", "password_expired", "pending", "deleted"}
+
+// accountTypes maps the type names of ACCOUNT_TYPE_RULES to account types.
+var accountTypes = map[string]models.AccountType{
+	"human":      models.AccountTypeHuman,
+	"service":    models.AccountTypeService,
+	"shared":     models.AccountTypeShared,
+	"privileged": models.AccountTypePrivileged,
+}
+
+// BindMechanism selects how directory connections authenticate.
+type BindMechanism string
+
+const (
+	BindMechanismSimple   BindMechanism = "simple"   // BIND_DN and BIND_PASS; anonymous without BIND_DN
+	BindMechanismGSSAPI   BindMechanism = "gssapi"   // Kerberos with a keytab
+	BindMechanismExternal BindMechanism = "external" // TLS client certificate
+)
+
+type TLSMode string
+
+const (
+	TLSModeNone     TLSMode = "none"
+	TLSModeLDAPS    TLSMode = "ldaps"
+	TLSModeStartTLS TLSMode = "starttls"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type SearchScope string
+
+const (
+	SearchScopeBase SearchScope = "base"
+	SearchScopeOne  SearchScope = "one"
+	SearchScopeSub  SearchScope = "sub"
 )
 
+// StalePolicy decides what the agent does when the startup sync does not
+// succeed in time, typically because an identity provider is unreachable.
+type StalePolicy string
+
+const (
+	StalePolicyFail  StalePolicy = "fail"  // exit; unreachable providers also fail the startup check
+	StalePolicyServe StalePolicy = "serve" // report ready with the users stored before the restart
+	StalePolicyWait  StalePolicy = "wait"  // stay not ready until a sync succeeds
+)
+
+// DefaultSourceName names the single identity provider configured through
+// the unprefixed IDP_* variables when IDP_SOURCES is not set.
+const DefaultSourceName = "default"
+
 type Config struct {
-	GRPC    GRPCConfig
-	IDP     IDPConfig
-	Storage StorageConfig
+	GRPC           GRPCConfig
+	IDPs           []IDPConfig
+	Storage        StorageConfig
+	Correlation    CorrelationConfig
+	Classification ClassificationConfig
+	SCIMServer     SCIMServerConfig
+	Startup        StartupConfig
 }
 
 type GRPCConfig struct {
@@ -28,19 +132,298 @@ func (g GRPCConfig) Address() string {
 	return fmt.Sprintf("%s:%d", g.Host, g.Port)
 }
 
+// SCIMServerConfig configures the optional SCIM 2.0 endpoint identity
+// providers push users to.
+type SCIMServerConfig struct {
+	Enabled bool
+	Host    string
+	Port    int
+	// Token is the bearer token identity providers must present.
+	Token string
+	// Source is the name pushed users are stored and hashed under.
+	Source      string
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// GraphConfig configures the Microsoft Graph client used by the entra_id
+// provider type.
+type GraphConfig struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// TokenURL overrides the Entra ID token endpoint derived from TenantID,
+	// e.g. to point at a local stand-in.
+	TokenURL string
+	BaseURL  string
+	PageSize int
+}
+
+// TokenEndpoint returns the OAuth2 token endpoint for client credentials.
+func (g GraphConfig) TokenEndpoint() string {
+	if g.TokenURL != "" {
+		return g.TokenURL
+	}
+	return fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", url.PathEscape(g.TenantID))
+}
+
+// GoogleConfig configures the Admin SDK Directory API client used by the
+// google_workspace provider type.
+type GoogleConfig struct {
+	// CredentialsFile is the JSON key of a service account with domain-wide
+	// delegation for the directory read-only scope.
+	CredentialsFile string
+	// Subject is the admin user the service account impersonates.
+	Subject  string
+	Customer string
+	Domain   string
+	// TokenURL and BaseURL override the Google endpoints, e.g. to point at a
+	// local fake.
+	TokenURL string
+	BaseURL  string
+	PageSize int
+}
+
+// FileConfig configures the file provider type, which reads users from a
+// CSV or JSON Lines export.
+type FileConfig struct {
+	Path   string
+	Format FileFormat
+	// Mapping maps user fields (see UserFields) to CSV headers or JSON keys.
+	// Unmapped fields are read from a column named like the field.
+	Mapping   map[string]string
+	Delimiter rune
+	// DisabledValues are status column values, compared case-insensitively,
+	// that mark a user as disabled.
+	DisabledValues []string
+	// PollInterval is how often the file is checked for changes.
+	PollInterval time.Duration
+}
+
+// HRConfig configures the hr provider type, which reads workers from a
+// paginated REST/JSON report such as Workday RaaS or a BambooHR custom
+// report.
+type HRConfig struct {
+	URL string
+	// Token is sent as a bearer token, Username and Password as basic auth.
+	Token    string
+	Username string
+	Password string
+	// RecordsField is the top-level key holding the worker array, e.g.
+	// "Report_Entry" for Workday RaaS. When empty the body itself must be an
+	// array.
+	RecordsField string
+	// NextField names the key holding the next page URL and requires
+	// RecordsField. Without it pages are requested with PageParam and
+	// PageSizeParam until a short page arrives; an empty PageParam disables
+	// paging. It defaults to empty for Workday RaaS, which returns the whole
+	// report in one response.
+	NextField     string
+	PageParam     string
+	PageSizeParam string
+	PageSize      int
+	// Mapping maps user fields (see UserFields) to worker keys. Nested keys
+	// are addressed with dots, e.g. "Manager.Employee_ID".
+	Mapping map[string]string
+	// StatusMap maps lower-cased employment states to one of UserStatuses.
+	StatusMap map[string]string
+}
+
+func (s SCIMServerConfig) Address() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
 type IDPConfig struct {
+	// Name identifies the source. It is stored on every user and scopes
+	// hashing and deletions.
+	Name     string
+	Schedule ScheduleConfig
+
 	Type     IdentityProviderType
 	Host     string
 	Port     int
 	BaseDN   string
 	BindDN   string
 	BindPass string
-	UseTLS   bool
+	TLS      TLSConfig
+
+	BindMechanism BindMechanism
+	Kerberos      KerberosConfig
+
+	// Servers lists ldap:// or ldaps:// URLs tried in order. When empty and
+	// DiscoveryDomain is set, servers are looked up through DNS SRV records;
+	// otherwise Host and Port are used.
+	Servers         []string
+	DiscoveryDomain string
+	// PoolSize is the number of idle bound connections kept per source.
+	PoolSize int
+	// ServerRetryInterval is how long a server that failed stays out of
+	// rotation before it is tried again, and how often preferred servers
+	// are rechecked after failing over.
+	ServerRetryInterval time.Duration
+
+	// SearchBases lists the subtrees users are read from. When empty, BaseDN
+	// is searched with subtree scope.
+	SearchBases []SearchBase
+	// UserFilter replaces the adapter's default user filter when set.
+	UserFilter string
+	// ExcludeDNSuffixes drops entries whose DN ends with any of the suffixes,
+	// e.g. service-account OUs.
+	ExcludeDNSuffixes []string
+
+	SCIM   SCIMConfig
+	Graph  GraphConfig
+	Google GoogleConfig
+	File   FileConfig
+	HR     HRConfig
+
+	envPrefix string
+}
+
+// ScheduleConfig controls when a source is synced. Incremental syncs run on
+// Interval, full reconciliations on FullInterval; a cron expression replaces
+// the matching interval when set. Cron expressions and quiet windows use the
+// agent's local time zone.
+type ScheduleConfig struct {
+	Interval     time.Duration
+	Cron         string
+	FullInterval time.Duration
+	FullCron     string
+	// Jitter delays every run by a random duration up to this value, so
+	// that sources and agents sharing a schedule do not start together.
+	Jitter time.Duration
+	// QuietWindows are daily time ranges during which no scheduled sync
+	// starts; runs falling inside one are postponed to its end.
+	QuietWindows []models.QuietWindow
+	// RetryBackoff is the delay before retrying a sync that failed with a
+	// transient error. It doubles with every consecutive failure up to
+	// RetryMaxBackoff.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+}
+
+// TLSConfig secures directory connections, either with LDAPS or by
+// upgrading a plain connection with StartTLS.
+type TLSConfig struct {
+	Mode TLSMode
+	// CAFile is a PEM bundle trusted in addition to the system roots, e.g.
+	// an internal AD CS root.
+	CAFile string
+	// ServerName is the name verified against the certificate; it defaults
+	// to the host.
+	ServerName string
+	// PinnedSHA256 lists hex SHA-256 fingerprints of accepted server
+	// certificates. When set, a matching leaf certificate is trusted in
+	// place of chain and hostname verification.
+	PinnedSHA256       []string
+	MinVersion         string
+	InsecureSkipVerify bool
+	// ClientCertFile and ClientKeyFile hold the PEM client certificate
+	// presented to the server, required by the external bind mechanism.
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// KerberosConfig configures the gssapi bind mechanism.
+type KerberosConfig struct {
+	KeytabFile string
+	// Principal is the keytab's user principal without the realm.
+	Principal string
+	// Realm defaults to the default_realm of ConfigFile.
+	Realm      string
+	ConfigFile string
+	// SPN is the directory's service principal; it defaults to ldap/<host>.
+	SPN string
+}
+
+// ServicePrincipal returns the SPN the gssapi mechanism requests a ticket
+// for when connecting to host.
+func (c IDPConfig) ServicePrincipal(host string) string {
+	if c.Kerberos.SPN != "" {
+		return c.Kerberos.SPN
+	}
+	return "ldap/" + host
+}
+
+// SRVName returns the DNS SRV name directory servers are discovered under:
+// the domain controller locator record for Active Directory, the generic
+// LDAP record otherwise.
+func (c IDPConfig) SRVName() string {
+	if c.Type == IdentityProviderTypeActiveDirectory {
+		return "_ldap._tcp.dc._msdcs." + c.DiscoveryDomain
+	}
+	return "_ldap._tcp." + c.DiscoveryDomain
+}
+
+// MinTLSVersion returns MinVersion as a crypto/tls constant.
+func (t TLSConfig) MinTLSVersion() uint16 {
+	return tlsVersions[t.MinVersion]
+}
+
+// SCIMConfig configures the SCIM 2.0 client used by the scim provider type.
+type SCIMConfig struct {
+	// BaseURL is the SCIM service root, e.g. https://example.okta.com/scim/v2.
+	BaseURL  string
+	Token    string
+	PageSize int
+}
+
+type SearchBase struct {
+	DN    string
+	Scope SearchScope
 }
 
 type StorageConfig struct {
 	Path     string
 	InMemory bool
+	// SyncRunRetention is how long the sync run history is kept.
+	SyncRunRetention time.Duration
+	// UserHistoryKey is the base64 AES-256 key encrypting the field values
+	// of user versions. User history is only recorded when it is set.
+	UserHistoryKey string
+	// UserHistoryRetention is how long user versions are kept.
+	UserHistoryRetention time.Duration
+	// ReadOnly opens the store without writing to it. It is set by commands
+	// such as diff rather than read from the environment.
+	ReadOnly bool
+}
+
+// HistoryKey decodes UserHistoryKey, or returns nil when user history is
+// off.
+func (s StorageConfig) HistoryKey() ([]byte, error) {
+	if s.UserHistoryKey == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(s.UserHistoryKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid USER_HISTORY_KEY: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid USER_HISTORY_KEY: must decode to 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// CorrelationConfig controls how accounts from different sources are linked
+// into persons. Keys are tried in order when deriving the person hash.
+type CorrelationConfig struct {
+	Keys []models.CorrelationKey
+}
+
+// StartupConfig bounds the initial sync run at boot. The agent reports ready
+// once every source completed it; StalePolicy applies when SyncTimeout passes
+// first.
+type StartupConfig struct {
+	SyncTimeout time.Duration
+	StalePolicy StalePolicy
+}
+
+// ClassificationConfig holds the account type rules applied to every synced
+// user. Rules are tried in order and the first match wins; users matching
+// none are human.
+type ClassificationConfig struct {
+	Rules []models.AccountTypeRule
 }
 
 func LoadFromEnv() (*Config, error) {
@@ -49,21 +432,60 @@ func LoadFromEnv() (*Config, error) {
 			Host: getEnv("GRPC_HOST", "0.0.0.0"),
 			Port: getEnvInt("GRPC_PORT", 50051),
 		},
-		IDP: IDPConfig{
-			Type:     IdentityProviderType(getEnv("IDP_TYPE", string(IdentityProviderTypeLDAP))),
-			Host:     getEnv("IDP_HOST", "localhost"),
-			Port:     getEnvInt("IDP_PORT", 389),
-			BaseDN:   getEnv("IDP_BASE_DN", ""),
-			BindDN:   getEnv("IDP_BIND_DN", ""),
-			BindPass: getEnv("IDP_BIND_PASS", ""),
-			UseTLS:   getEnvBool("IDP_USE_TLS", false),
-		},
 		Storage: StorageConfig{
 			Path:     getEnv("STORAGE_PATH", "./data"),
 			InMemory: getEnvBool("STORAGE_IN_MEMORY", false),
+
+			SyncRunRetention: getEnvDuration("SYNC_RUN_RETENTION", 30*24*time.Hour),
+
+			UserHistoryKey:       getEnv("USER_HISTORY_KEY", ""),
+			UserHistoryRetention: getEnvDuration("USER_HISTORY_RETENTION", 365*24*time.Hour),
+		},
+		Startup: StartupConfig{
+			SyncTimeout: getEnvDuration("STARTUP_SYNC_TIMEOUT", 10*time.Minute),
+			StalePolicy: StalePolicy(getEnv("STARTUP_STALE_POLICY", string(StalePolicyFail))),
 		},
 	}
 
+	cfg.SCIMServer = SCIMServerConfig{
+		Enabled:     getEnvBool("SCIM_SERVER_ENABLED", false),
+		Host:        getEnv("SCIM_SERVER_HOST", "0.0.0.0"),
+		Port:        getEnvInt("SCIM_SERVER_PORT", 8080),
+		Token:       getEnv("SCIM_SERVER_TOKEN", ""),
+		Source:      getEnv("SCIM_SERVER_SOURCE", "scim"),
+		TLSCertFile: getEnv("SCIM_SERVER_TLS_CERT_FILE", ""),
+		TLSKeyFile:  getEnv("SCIM_SERVER_TLS_KEY_FILE", ""),
+	}
+
+	for _, key := range strings.Split(getEnv("CORRELATION_KEYS", "employee_id,email"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			cfg.Correlation.Keys = append(cfg.Correlation.Keys, models.CorrelationKey(key))
+		}
+	}
+
+	rules, err := parseAccountTypeRules(getEnv("ACCOUNT_TYPE_RULES", "service=spn;privileged=privileged"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACCOUNT_TYPE_RULES: %w", err)
+	}
+	cfg.Classification.Rules = rules
+
+	sources := getEnvList("IDP_SOURCES", ",")
+	if len(sources) == 0 {
+		idp, err := loadIDPConfig(DefaultSourceName, "IDP_")
+		if err != nil {
+			return nil, err
+		}
+		cfg.IDPs = append(cfg.IDPs, idp)
+	}
+
+	for _, name := range sources {
+		idp, err := loadIDPConfig(name, "IDP_"+strings.ToUpper(name)+"_")
+		if err != nil {
+			return nil, err
+		}
+		cfg.IDPs = append(cfg.IDPs, idp)
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
@@ -71,21 +493,772 @@ func LoadFromEnv() (*Config, error) {
 	return cfg, nil
 }
 
+// loadIDPConfig reads the settings of a single source from variables sharing
+// prefix, e.g. IDP_CORP_HOST for the "corp" source.
+func loadIDPConfig(name, prefix string) (IDPConfig, error) {
+	idp := IDPConfig{
+		Name: name,
+		Schedule: ScheduleConfig{
+			Interval:     getEnvDuration(prefix+"SYNC_INTERVAL", time.Minute),
+			Cron:         getEnv(prefix+"SYNC_CRON", ""),
+			FullInterval: getEnvDuration(prefix+"FULL_SYNC_INTERVAL", 24*time.Hour),
+			FullCron:     getEnv(prefix+"FULL_SYNC_CRON", ""),
+			Jitter:       getEnvDuration(prefix+"SYNC_JITTER", 0),
+
+			RetryBackoff:    getEnvDuration(prefix+"SYNC_RETRY_BACKOFF", 10*time.Second),
+			RetryMaxBackoff: getEnvDuration(prefix+"SYNC_RETRY_MAX_BACKOFF", 15*time.Minute),
+		},
+
+		Type:     IdentityProviderType(getEnv(prefix+"TYPE", string(IdentityProviderTypeLDAP))),
+		Host:     getEnv(prefix+"HOST", "localhost"),
+		BaseDN:   getEnv(prefix+"BASE_DN", ""),
+		BindDN:   getEnv(prefix+"BIND_DN", ""),
+		BindPass: getEnv(prefix+"BIND_PASS", ""),
+
+		TLS: TLSConfig{
+			CAFile:             getEnv(prefix+"TLS_CA_FILE", ""),
+			ServerName:         getEnv(prefix+"TLS_SERVER_NAME", ""),
+			PinnedSHA256:       getEnvList(prefix+"TLS_PINNED_SHA256", ","),
+			MinVersion:         getEnv(prefix+"TLS_MIN_VERSION", "1.2"),
+			InsecureSkipVerify: getEnvBool(prefix+"TLS_INSECURE_SKIP_VERIFY", false),
+			ClientCertFile:     getEnv(prefix+"TLS_CLIENT_CERT_FILE", ""),
+			ClientKeyFile:      getEnv(prefix+"TLS_CLIENT_KEY_FILE", ""),
+		},
+
+		Servers:             getEnvList(prefix+"SERVERS", ","),
+		DiscoveryDomain:     getEnv(prefix+"DISCOVERY_DOMAIN", ""),
+		PoolSize:            getEnvInt(prefix+"POOL_SIZE", 2),
+		ServerRetryInterval: getEnvDuration(prefix+"SERVER_RETRY_INTERVAL", 30*time.Second),
+
+		BindMechanism: BindMechanism(getEnv(prefix+"BIND_MECHANISM", string(BindMechanismSimple))),
+		Kerberos: KerberosConfig{
+			KeytabFile: getEnv(prefix+"KRB5_KEYTAB", ""),
+			Principal:  getEnv(prefix+"KRB5_PRINCIPAL", ""),
+			Realm:      getEnv(prefix+"KRB5_REALM", ""),
+			ConfigFile: getEnv(prefix+"KRB5_CONFIG", "/etc/krb5.conf"),
+			SPN:        getEnv(prefix+"KRB5_SPN", ""),
+		},
+
+		UserFilter:        getEnv(prefix+"USER_FILTER", ""),
+		ExcludeDNSuffixes: getEnvList(prefix+"EXCLUDE_DN_SUFFIXES", ";"),
+
+		SCIM: SCIMConfig{
+			BaseURL:  getEnv(prefix+"SCIM_BASE_URL", ""),
+			Token:    getEnv(prefix+"SCIM_TOKEN", ""),
+			PageSize: getEnvInt(prefix+"SCIM_PAGE_SIZE", 100),
+		},
+
+		Graph: GraphConfig{
+			TenantID:     getEnv(prefix+"GRAPH_TENANT_ID", ""),
+			ClientID:     getEnv(prefix+"GRAPH_CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"GRAPH_CLIENT_SECRET", ""),
+			TokenURL:     getEnv(prefix+"GRAPH_TOKEN_URL", ""),
+			BaseURL:      getEnv(prefix+"GRAPH_BASE_URL", "https://graph.microsoft.com/v1.0"),
+			PageSize:     getEnvInt(prefix+"GRAPH_PAGE_SIZE", 999),
+		},
+
+		Google: GoogleConfig{
+			CredentialsFile: getEnv(prefix+"GOOGLE_CREDENTIALS_FILE", ""),
+			Subject:         getEnv(prefix+"GOOGLE_SUBJECT", ""),
+			Customer:        getEnv(prefix+"GOOGLE_CUSTOMER", "my_customer"),
+			Domain:          getEnv(prefix+"GOOGLE_DOMAIN", ""),
+			TokenURL:        getEnv(prefix+"GOOGLE_TOKEN_URL", ""),
+			BaseURL:         getEnv(prefix+"GOOGLE_BASE_URL", "https://admin.googleapis.com"),
+			PageSize:        getEnvInt(prefix+"GOOGLE_PAGE_SIZE", 500),
+		},
+
+		File: FileConfig{
+			Path:           getEnv(prefix+"FILE_PATH", ""),
+			Format:         FileFormat(getEnv(prefix+"FILE_FORMAT", "")),
+			Delimiter:      []rune(getEnv(prefix+"FILE_DELIMITER", ","))[0],
+			DisabledValues: getEnvList(prefix+"FILE_DISABLED_VALUES", ","),
+			PollInterval:   getEnvDuration(prefix+"FILE_POLL_INTERVAL", 30*time.Second),
+		},
+
+		HR: HRConfig{
+			URL:           getEnv(prefix+"HR_URL", ""),
+			Token:         getEnv(prefix+"HR_TOKEN", ""),
+			Username:      getEnv(prefix+"HR_USERNAME", ""),
+			Password:      getEnv(prefix+"HR_PASSWORD", ""),
+			RecordsField:  getEnv(prefix+"HR_RECORDS_FIELD", "Report_Entry"),
+			NextField:     getEnv(prefix+"HR_NEXT_FIELD", ""),
+			PageSizeParam: getEnv(prefix+"HR_PAGE_SIZE_PARAM", "limit"),
+			PageSize:      getEnvInt(prefix+"HR_PAGE_SIZE", 100),
+		},
+
+		envPrefix: prefix,
+	}
+
+	// USE_TLS predates TLS_MODE and still selects LDAPS when the mode is unset.
+	idp.TLS.Mode = TLSMode(getEnv(prefix+"TLS_MODE", ""))
+	if idp.TLS.Mode == "" {
+		idp.TLS.Mode = TLSModeNone
+		if getEnvBool(prefix+"USE_TLS", false) {
+			idp.TLS.Mode = TLSModeLDAPS
+		}
+	}
+
+	defaultPort := 389
+	if idp.TLS.Mode == TLSModeLDAPS {
+		defaultPort = 636
+	}
+	idp.Port = getEnvInt(prefix+"PORT", defaultPort)
+
+	if idp.File.Format == "" {
+		idp.File.Format = FileFormatCSV
+		if ext := strings.ToLower(filepath.Ext(idp.File.Path)); ext == ".jsonl" || ext == ".ndjson" {
+			idp.File.Format = FileFormatJSONL
+		}
+	}
+
+	if len(idp.File.DisabledValues) == 0 {
+		idp.File.DisabledValues = []string{"disabled", "inactive", "terminated", "false", "0"}
+	}
+
+	mapping, err := parseMapping(getEnv(prefix+"FILE_MAPPING", ""))
+	if err != nil {
+		return IDPConfig{}, fmt.Errorf("invalid %sFILE_MAPPING: %w", prefix, err)
+	}
+	idp.File.Mapping = mapping
+
+	// Workday RaaS ignores paging parameters and returns the whole report.
+	defaultPageParam := "page"
+	if idp.HR.RecordsField == "Report_Entry" {
+		defaultPageParam = ""
+	}
+	idp.HR.PageParam = getEnv(prefix+"HR_PAGE_PARAM", defaultPageParam)
+
+	if idp.HR.Mapping, err = parseMapping(getEnv(prefix+"HR_MAPPING", "")); err != nil {
+		return IDPConfig{}, fmt.Errorf("invalid %sHR_MAPPING: %w", prefix, err)
+	}
+
+	statusMap, err := parseMapping(getEnv(prefix+"HR_STATUS_MAP",
+		"active=active,terminated=disabled,inactive=disabled,on leave=disabled,pre-hire=pending"))
+	if err != nil {
+		return IDPConfig{}, fmt.Errorf("invalid %sHR_STATUS_MAP: %w", prefix, err)
+	}
+	idp.HR.StatusMap = make(map[string]string, len(statusMap))
+	for state, status := range statusMap {
+		idp.HR.StatusMap[strings.ToLower(state)] = strings.ToLower(status)
+	}
+
+	quietWindows, err := parseQuietWindows(getEnv(prefix+"SYNC_QUIET_WINDOWS", ""))
+	if err != nil {
+		return IDPConfig{}, fmt.Errorf("invalid %sSYNC_QUIET_WINDOWS: %w", prefix, err)
+	}
+	idp.Schedule.QuietWindows = quietWindows
+
+	searchBases, err := parseSearchBases(getEnv(prefix+"SEARCH_BASES", ""))
+	if err != nil {
+		return IDPConfig{}, fmt.Errorf("invalid %sSEARCH_BASES: %w", prefix, err)
+	}
+	idp.SearchBases = searchBases
+
+	return idp, nil
+}
+
 func (c *Config) Validate() error {
-	switch c.IDP.Type {
+	if len(c.IDPs) == 0 {
+		return fmt.Errorf("at least one identity provider is required")
+	}
+
+	names := make(map[string]struct{}, len(c.IDPs))
+	for _, idp := range c.IDPs {
+		if idp.Name == "" {
+			return fmt.Errorf("identity provider name is required")
+		}
+		if _, ok := names[idp.Name]; ok {
+			return fmt.Errorf("duplicate identity provider name: %s", idp.Name)
+		}
+		names[idp.Name] = struct{}{}
+
+		if err := idp.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.SCIMServer.Enabled {
+		if c.SCIMServer.Token == "" {
+			return fmt.Errorf("SCIM_SERVER_TOKEN is required when SCIM_SERVER_ENABLED is set")
+		}
+		if c.SCIMServer.Source == "" {
+			return fmt.Errorf("SCIM_SERVER_SOURCE is required when SCIM_SERVER_ENABLED is set")
+		}
+		if _, ok := names[c.SCIMServer.Source]; ok {
+			return fmt.Errorf("SCIM_SERVER_SOURCE %s collides with an identity provider name", c.SCIMServer.Source)
+		}
+		if (c.SCIMServer.TLSCertFile == "") != (c.SCIMServer.TLSKeyFile == "") {
+			return fmt.Errorf("SCIM_SERVER_TLS_CERT_FILE and SCIM_SERVER_TLS_KEY_FILE must be set together")
+		}
+	}
+
+	for _, key := range c.Correlation.Keys {
+		switch key {
+		case models.CorrelationKeyEmployeeID, models.CorrelationKeyEmail:
+		default:
+			return fmt.Errorf("invalid CORRELATION_KEYS entry: %s, must be one of: %s, %s",
+				key, models.CorrelationKeyEmployeeID, models.CorrelationKeyEmail)
+		}
+	}
+
+	for i, rule := range c.Classification.Rules {
+		if err := validateAccountTypeRule(rule); err != nil {
+			return fmt.Errorf("invalid ACCOUNT_TYPE_RULES entry %d: %w", i+1, err)
+		}
+	}
+
+	if c.Storage.SyncRunRetention <= 0 {
+		return fmt.Errorf("SYNC_RUN_RETENTION must be positive")
+	}
+
+	if c.Storage.UserHistoryKey != "" {
+		if _, err := c.Storage.HistoryKey(); err != nil {
+			return err
+		}
+	}
+
+	if c.Storage.UserHistoryRetention <= 0 {
+		return fmt.Errorf("USER_HISTORY_RETENTION must be positive")
+	}
+
+	if c.Startup.SyncTimeout <= 0 {
+		return fmt.Errorf("STARTUP_SYNC_TIMEOUT must be positive")
+	}
+
+	switch c.Startup.StalePolicy {
+	case StalePolicyFail, StalePolicyServe, StalePolicyWait:
+	default:
+		return fmt.Errorf("invalid STARTUP_STALE_POLICY: %s, must be one of: %s, %s, %s",
+			c.Startup.StalePolicy, StalePolicyFail, StalePolicyServe, StalePolicyWait)
+	}
+
+	return nil
+}
+
+func validateAccountTypeRule(r models.AccountTypeRule) error {
+	switch r.Match {
+	case models.AccountTypeMatchOU, models.AccountTypeMatchName:
+		if r.Pattern == "" {
+			return fmt.Errorf("%s requires a pattern", r.Match)
+		}
+		if r.Match == models.AccountTypeMatchName {
+			if _, err := regexp.Compile(r.Pattern); err != nil {
+				return fmt.Errorf("invalid name pattern: %w", err)
+			}
+		}
+	case models.AccountTypeMatchNoEmployeeID, models.AccountTypeMatchPrivileged, models.AccountTypeMatchSPN:
+		if r.Pattern != "" {
+			return fmt.Errorf("%s takes no pattern", r.Match)
+		}
+	default:
+		return fmt.Errorf("unknown match: %s, must be one of: %s, %s, %s, %s, %s", r.Match,
+			models.AccountTypeMatchOU, models.AccountTypeMatchName, models.AccountTypeMatchNoEmployeeID,
+			models.AccountTypeMatchPrivileged, models.AccountTypeMatchSPN)
+	}
+
+	return nil
+}
+
+func (c IDPConfig) Validate() error {
+	p := c.prefix()
+
+	if err := c.validateSchedule(); err != nil {
+		return err
+	}
+
+	switch c.Type {
 	case IdentityProviderTypeActiveDirectory, IdentityProviderTypeLDAP:
+		return c.validateDirectory()
+	case IdentityProviderTypeSCIM:
+		return c.validateSCIM()
+	case IdentityProviderTypeEntraID:
+		return c.validateGraph()
+	case IdentityProviderTypeGoogleWorkspace:
+		return c.validateGoogle()
+	case IdentityProviderTypeFile:
+		return c.validateFile()
+	case IdentityProviderTypeHR:
+		return c.validateHR()
+	default:
+		return fmt.Errorf("invalid %sTYPE: %s, must be one of: %s",
+			p, c.Type, strings.Join(identityProviderTypes, ", "))
+	}
+}
+
+func (c IDPConfig) validateSchedule() error {
+	p := c.prefix()
+	s := c.Schedule
+
+	if s.Cron != "" {
+		if _, err := cron.ParseStandard(s.Cron); err != nil {
+			return fmt.Errorf("invalid %sSYNC_CRON: %w", p, err)
+		}
+	} else if s.Interval <= 0 {
+		return fmt.Errorf("%sSYNC_INTERVAL must be positive", p)
+	}
+
+	if s.FullCron != "" {
+		if _, err := cron.ParseStandard(s.FullCron); err != nil {
+			return fmt.Errorf("invalid %sFULL_SYNC_CRON: %w", p, err)
+		}
+	} else if s.FullInterval <= 0 {
+		return fmt.Errorf("%sFULL_SYNC_INTERVAL must be positive", p)
+	}
+
+	if s.Jitter < 0 {
+		return fmt.Errorf("%sSYNC_JITTER must not be negative", p)
+	}
+
+	if s.RetryBackoff <= 0 {
+		return fmt.Errorf("%sSYNC_RETRY_BACKOFF must be positive", p)
+	}
+	if s.RetryMaxBackoff < s.RetryBackoff {
+		return fmt.Errorf("%sSYNC_RETRY_MAX_BACKOFF must not be less than %sSYNC_RETRY_BACKOFF", p, p)
+	}
+
+	if len(s.QuietWindows) > 0 && quietAllDay(s.QuietWindows) {
+		return fmt.Errorf("%sSYNC_QUIET_WINDOWS must leave part of the day for syncs", p)
+	}
+
+	return nil
+}
+
+func (c IDPConfig) validateDirectory() error {
+	p := c.prefix()
+
+	if c.Host == "" && len(c.Servers) == 0 && c.DiscoveryDomain == "" {
+		return fmt.Errorf("%sHOST, %sSERVERS or %sDISCOVERY_DOMAIN is required", p, p, p)
+	}
+
+	for _, server := range c.Servers {
+		u, err := url.Parse(server)
+		if err != nil || u.Hostname() == "" || (u.Scheme != "ldap" && u.Scheme != "ldaps") {
+			return fmt.Errorf("invalid %sSERVERS entry %q: expected ldap://host[:port] or ldaps://host[:port]", p, server)
+		}
+		if (u.Scheme == "ldaps") != (c.TLS.Mode == TLSModeLDAPS) {
+			return fmt.Errorf("%sSERVERS entry %q does not match %sTLS_MODE=%s", p, server, p, c.TLS.Mode)
+		}
+	}
+
+	if c.PoolSize < 1 {
+		return fmt.Errorf("%sPOOL_SIZE must be at least 1", p)
+	}
+
+	if c.ServerRetryInterval <= 0 {
+		return fmt.Errorf("%sSERVER_RETRY_INTERVAL must be positive", p)
+	}
+
+	if c.BaseDN == "" && len(c.SearchBases) == 0 {
+		return fmt.Errorf("%sBASE_DN or %sSEARCH_BASES is required", p, p)
+	}
+
+	for _, base := range c.SearchBases {
+		if _, err := ldap.ParseDN(base.DN); err != nil {
+			return fmt.Errorf("invalid search base %q: %w", base.DN, err)
+		}
+		switch base.Scope {
+		case SearchScopeBase, SearchScopeOne, SearchScopeSub:
+		default:
+			return fmt.Errorf("invalid scope %q for search base %q, must be one of: %s, %s, %s",
+				base.Scope, base.DN, SearchScopeBase, SearchScopeOne, SearchScopeSub)
+		}
+	}
+
+	if c.UserFilter != "" {
+		if _, err := ldap.CompileFilter(c.UserFilter); err != nil {
+			return fmt.Errorf("invalid %sUSER_FILTER: %w", p, err)
+		}
+	}
+
+	for _, suffix := range c.ExcludeDNSuffixes {
+		if _, err := ldap.ParseDN(suffix); err != nil {
+			return fmt.Errorf("invalid %sEXCLUDE_DN_SUFFIXES entry %q: %w", p, suffix, err)
+		}
+	}
+
+	if err := c.validateTLS(); err != nil {
+		return err
+	}
+
+	return c.validateBind()
+}
+
+func (c IDPConfig) validateBind() error {
+	p := c.prefix()
+
+	switch c.BindMechanism {
+	case BindMechanismSimple:
+	case BindMechanismGSSAPI:
+		if c.Kerberos.KeytabFile == "" || c.Kerberos.Principal == "" {
+			return fmt.Errorf("%sBIND_MECHANISM=gssapi requires %sKRB5_KEYTAB and %sKRB5_PRINCIPAL", p, p, p)
+		}
+		for _, file := range []string{c.Kerberos.KeytabFile, c.Kerberos.ConfigFile} {
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("%sBIND_MECHANISM=gssapi: %w", p, err)
+			}
+		}
+	case BindMechanismExternal:
+		if c.TLS.Mode == TLSModeNone {
+			return fmt.Errorf("%sBIND_MECHANISM=external requires %sTLS_MODE ldaps or starttls", p, p)
+		}
+		if c.TLS.ClientCertFile == "" || c.TLS.ClientKeyFile == "" {
+			return fmt.Errorf("%sBIND_MECHANISM=external requires %sTLS_CLIENT_CERT_FILE and %sTLS_CLIENT_KEY_FILE", p, p, p)
+		}
 	default:
-		return fmt.Errorf("invalid IDP_TYPE: %s, must be one of: %s, %s",
-			c.IDP.Type, IdentityProviderTypeActiveDirectory, IdentityProviderTypeLDAP)
+		return fmt.Errorf("invalid %sBIND_MECHANISM: %s, must be one of: %s, %s, %s",
+			p, c.BindMechanism, BindMechanismSimple, BindMechanismGSSAPI, BindMechanismExternal)
 	}
 
-	if c.IDP.Host == "" {
-		return fmt.Errorf("IDP_HOST is required")
+	if c.BindMechanism != BindMechanismSimple && c.BindPass != "" {
+		return fmt.Errorf("%sBIND_PASS must not be set with %sBIND_MECHANISM=%s", p, p, c.BindMechanism)
+	}
+
+	if (c.TLS.ClientCertFile == "") != (c.TLS.ClientKeyFile == "") {
+		return fmt.Errorf("%sTLS_CLIENT_CERT_FILE and %sTLS_CLIENT_KEY_FILE must be set together", p, p)
 	}
 
 	return nil
 }
 
+func (c IDPConfig) validateTLS() error {
+	p := c.prefix()
+
+	switch c.TLS.Mode {
+	case TLSModeNone:
+		return nil
+	case TLSModeLDAPS, TLSModeStartTLS:
+	default:
+		return fmt.Errorf("invalid %sTLS_MODE: %s, must be one of: %s, %s, %s",
+			p, c.TLS.Mode, TLSModeNone, TLSModeLDAPS, TLSModeStartTLS)
+	}
+
+	if _, ok := tlsVersions[c.TLS.MinVersion]; !ok {
+		return fmt.Errorf("invalid %sTLS_MIN_VERSION: %s, must be one of: 1.0, 1.1, 1.2, 1.3", p, c.TLS.MinVersion)
+	}
+
+	if c.TLS.CAFile != "" {
+		if _, err := os.Stat(c.TLS.CAFile); err != nil {
+			return fmt.Errorf("invalid %sTLS_CA_FILE: %w", p, err)
+		}
+	}
+
+	for _, pin := range c.TLS.PinnedSHA256 {
+		if raw, err := hex.DecodeString(strings.ReplaceAll(pin, ":", "")); err != nil || len(raw) != sha256.Size {
+			return fmt.Errorf("invalid %sTLS_PINNED_SHA256 entry %q: expected a hex SHA-256 fingerprint", p, pin)
+		}
+	}
+
+	return nil
+}
+
+func (c IDPConfig) validateSCIM() error {
+	p := c.prefix()
+
+	if err := validateHTTPURL(c.SCIM.BaseURL); err != nil {
+		return fmt.Errorf("invalid %sSCIM_BASE_URL: %w", p, err)
+	}
+
+	if c.SCIM.Token == "" {
+		return fmt.Errorf("%sSCIM_TOKEN is required", p)
+	}
+
+	if c.SCIM.PageSize <= 0 {
+		return fmt.Errorf("%sSCIM_PAGE_SIZE must be positive", p)
+	}
+
+	return nil
+}
+
+func (c IDPConfig) validateGraph() error {
+	p := c.prefix()
+
+	if c.Graph.TenantID == "" && c.Graph.TokenURL == "" {
+		return fmt.Errorf("%sGRAPH_TENANT_ID or %sGRAPH_TOKEN_URL is required", p, p)
+	}
+
+	if c.Graph.TokenURL != "" {
+		if err := validateHTTPURL(c.Graph.TokenURL); err != nil {
+			return fmt.Errorf("invalid %sGRAPH_TOKEN_URL: %w", p, err)
+		}
+	}
+
+	if c.Graph.ClientID == "" || c.Graph.ClientSecret == "" {
+		return fmt.Errorf("%sGRAPH_CLIENT_ID and %sGRAPH_CLIENT_SECRET are required", p, p)
+	}
+
+	if err := validateHTTPURL(c.Graph.BaseURL); err != nil {
+		return fmt.Errorf("invalid %sGRAPH_BASE_URL: %w", p, err)
+	}
+
+	if c.Graph.PageSize <= 0 || c.Graph.PageSize > 999 {
+		return fmt.Errorf("%sGRAPH_PAGE_SIZE must be between 1 and 999", p)
+	}
+
+	return nil
+}
+
+func (c IDPConfig) validateGoogle() error {
+	p := c.prefix()
+
+	if c.Google.CredentialsFile == "" {
+		return fmt.Errorf("%sGOOGLE_CREDENTIALS_FILE is required", p)
+	}
+
+	if c.Google.Subject == "" {
+		return fmt.Errorf("%sGOOGLE_SUBJECT is required for domain-wide delegation", p)
+	}
+
+	if c.Google.TokenURL != "" {
+		if err := validateHTTPURL(c.Google.TokenURL); err != nil {
+			return fmt.Errorf("invalid %sGOOGLE_TOKEN_URL: %w", p, err)
+		}
+	}
+
+	if err := validateHTTPURL(c.Google.BaseURL); err != nil {
+		return fmt.Errorf("invalid %sGOOGLE_BASE_URL: %w", p, err)
+	}
+
+	if c.Google.PageSize <= 0 || c.Google.PageSize > 500 {
+		return fmt.Errorf("%sGOOGLE_PAGE_SIZE must be between 1 and 500", p)
+	}
+
+	return nil
+}
+
+func (c IDPConfig) validateFile() error {
+	p := c.prefix()
+
+	if c.File.Path == "" {
+		return fmt.Errorf("%sFILE_PATH is required", p)
+	}
+
+	switch c.File.Format {
+	case FileFormatCSV, FileFormatJSONL:
+	default:
+		return fmt.Errorf("invalid %sFILE_FORMAT: %s, must be one of: %s, %s",
+			p, c.File.Format, FileFormatCSV, FileFormatJSONL)
+	}
+
+	if err := validateMapping(c.File.Mapping); err != nil {
+		return fmt.Errorf("invalid %sFILE_MAPPING: %w", p, err)
+	}
+
+	if c.File.PollInterval <= 0 {
+		return fmt.Errorf("%sFILE_POLL_INTERVAL must be positive", p)
+	}
+
+	return nil
+}
+
+func (c IDPConfig) validateHR() error {
+	p := c.prefix()
+
+	if err := validateHTTPURL(c.HR.URL); err != nil {
+		return fmt.Errorf("invalid %sHR_URL: %w", p, err)
+	}
+
+	if c.HR.Token != "" && c.HR.Username != "" {
+		return fmt.Errorf("%sHR_TOKEN and %sHR_USERNAME are mutually exclusive", p, p)
+	}
+
+	if c.HR.NextField != "" && c.HR.RecordsField == "" {
+		return fmt.Errorf("%sHR_NEXT_FIELD requires %sHR_RECORDS_FIELD", p, p)
+	}
+
+	if c.HR.PageParam != "" && c.HR.PageSize <= 0 {
+		return fmt.Errorf("%sHR_PAGE_SIZE must be positive", p)
+	}
+
+	if err := validateMapping(c.HR.Mapping); err != nil {
+		return fmt.Errorf("invalid %sHR_MAPPING: %w", p, err)
+	}
+
+	for state, status := range c.HR.StatusMap {
+		if !slices.Contains(UserStatuses, status) {
+			return fmt.Errorf("invalid %sHR_STATUS_MAP status for %q: %s, must be one of: %s",
+				p, state, status, strings.Join(UserStatuses, ", "))
+		}
+	}
+
+	return nil
+}
+
+// prefix returns the environment variable prefix the source was loaded from,
+// used to point validation errors at the offending variable.
+func (c IDPConfig) prefix() string {
+	if c.envPrefix != "" {
+		return c.envPrefix
+	}
+	return "IDP_"
+}
+
+// Bases returns the configured search bases, falling back to a subtree
+// search of BaseDN.
+func (c IDPConfig) Bases() []SearchBase {
+	if len(c.SearchBases) > 0 {
+		return c.SearchBases
+	}
+	return []SearchBase{{DN: c.BaseDN, Scope: SearchScopeSub}}
+}
+
+func validateHTTPURL(value string) error {
+	if value == "" {
+		return fmt.Errorf("value is required")
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+
+	return nil
+}
+
+func validateMapping(mapping map[string]string) error {
+	for field := range mapping {
+		if !slices.Contains(UserFields, field) {
+			return fmt.Errorf("unknown field: %s, must be one of: %s", field, strings.Join(UserFields, ", "))
+		}
+	}
+	return nil
+}
+
+// parseAccountTypeRules parses "type=match[:pattern];..." where patterns may
+// contain commas, as distinguished names do.
+func parseAccountTypeRules(value string) ([]models.AccountTypeRule, error) {
+	var rules []models.AccountTypeRule
+	for _, part := range strings.Split(value, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		accountType, condition, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected type=match[:pattern], got %q", part)
+		}
+
+		typ, ok := accountTypes[strings.TrimSpace(accountType)]
+		if !ok {
+			return nil, fmt.Errorf("unknown account type: %s, must be one of: human, service, shared, privileged",
+				strings.TrimSpace(accountType))
+		}
+
+		match, pattern, _ := strings.Cut(condition, ":")
+		rules = append(rules, models.AccountTypeRule{
+			Type:    typ,
+			Match:   models.AccountTypeMatch(strings.TrimSpace(match)),
+			Pattern: strings.TrimSpace(pattern),
+		})
+	}
+	return rules, nil
+}
+
+// parseMapping parses "field=column,field=column".
+func parseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		field, column, ok := strings.Cut(part, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || field == "" || column == "" {
+			return nil, fmt.Errorf("expected field=column, got %q", part)
+		}
+
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+// parseSearchBases parses "dn?scope;dn?scope". The scope defaults to sub.
+func parseSearchBases(value string) ([]SearchBase, error) {
+	var bases []SearchBase
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		dn, scope, found := strings.Cut(part, "?")
+		base := SearchBase{DN: strings.TrimSpace(dn), Scope: SearchScopeSub}
+		if found {
+			base.Scope = SearchScope(strings.ToLower(strings.TrimSpace(scope)))
+		}
+		if base.DN == "" {
+			return nil, fmt.Errorf("empty DN in %q", part)
+		}
+
+		bases = append(bases, base)
+	}
+	return bases, nil
+}
+
+// parseQuietWindows parses "HH:MM-HH:MM,HH:MM-HH:MM".
+func parseQuietWindows(value string) ([]models.QuietWindow, error) {
+	var windows []models.QuietWindow
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		start, end, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf("expected HH:MM-HH:MM, got %q", part)
+		}
+
+		var w models.QuietWindow
+		var err error
+		if w.Start, err = parseClock(start); err != nil {
+			return nil, fmt.Errorf("%q: %w", part, err)
+		}
+		if w.End, err = parseClock(end); err != nil {
+			return nil, fmt.Errorf("%q: %w", part, err)
+		}
+		if w.Start == w.End {
+			return nil, fmt.Errorf("%q is empty", part)
+		}
+
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// quietAllDay reports whether windows leave no minute of the day free.
+func quietAllDay(windows []models.QuietWindow) bool {
+	for t := time.Duration(0); t < 24*time.Hour; t += time.Minute {
+		if !slices.ContainsFunc(windows, func(w models.QuietWindow) bool { return w.Contains(t) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseClock parses "HH:MM" into an offset from midnight. "24:00" is
+// accepted as the end of the day.
+func parseClock(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -110,3 +1283,22 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationVal, err := time.ParseDuration(value); err == nil {
+			return durationVal
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key, sep string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), sep) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,32 @@
+package models
+
+import "errors"
+
+// ErrPermanent matches errors that retrying cannot fix, such as rejected
+// credentials or a search base that does not exist. Sync failures are
+// otherwise treated as transient and retried with backoff.
+var ErrPermanent = errors.New("permanent error")
+
+// Permanent marks err as permanent without changing its message.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+func (e permanentError) Is(target error) bool {
+	return target == ErrPermanent
+}
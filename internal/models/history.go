@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserVersion records one change of a stored user. Field names are kept in
+// the clear, while their old and new values are PII and only stored sealed.
+type UserVersion struct {
+	// ID orders the versions of a user by time.
+	ID        string       `json:"id"`
+	UserHash  string       `json:"user_hash"`
+	Source    string       `json:"source"`
+	Action    ChangeAction `json:"action"`
+	ChangedAt time.Time    `json:"changed_at"`
+	Fields    []string     `json:"fields,omitempty"`
+	Sealed    []byte       `json:"sealed"`
+
+	// Changes holds the values once Sealed is opened. It is never stored.
+	Changes []FieldChange `json:"-"`
+}
+
+// FieldChange holds the JSON encoded values of one field before and after a
+// change. A missing value is the field's zero value.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
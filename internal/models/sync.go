@@ -0,0 +1,137 @@
+package models
+
+import "time"
+
+// SyncMode selects between reconciling the full user set of a source and
+// applying only the changes reported by the provider.
+type SyncMode int
+
+const (
+	SyncModeUnspecified SyncMode = iota
+	SyncModeFull
+	SyncModeIncremental
+)
+
+func (m SyncMode) String() string {
+	if m == SyncModeIncremental {
+		return "incremental"
+	}
+	return "full"
+}
+
+// QuietWindow is a daily time range, given as offsets from local midnight,
+// during which scheduled syncs do not start. A window whose start is after
+// its end spans midnight.
+type QuietWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Contains reports whether the offset from midnight t lies in the window.
+func (w QuietWindow) Contains(t time.Duration) bool {
+	if w.Start < w.End {
+		return t >= w.Start && t < w.End
+	}
+	return t >= w.Start || t < w.End
+}
+
+type SyncRunState int
+
+const (
+	SyncRunStateUnspecified SyncRunState = iota
+	SyncRunStateRunning
+	SyncRunStateSucceeded
+	SyncRunStateFailed
+	SyncRunStateQueued // triggered, waiting for the sync in progress to end
+)
+
+// SyncStats counts what a sync did to the stored users of its source.
+type SyncStats struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Unchanged int `json:"unchanged"`
+}
+
+// SyncRun records one sync of a source. IDs sort in start order.
+type SyncRun struct {
+	ID         string       `json:"id"`
+	Source     string       `json:"source"`
+	Mode       SyncMode     `json:"mode"`
+	State      SyncRunState `json:"state"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at,omitzero"`
+	Error      string       `json:"error,omitempty"`
+	Stats      SyncStats    `json:"stats"`
+}
+
+// SyncStatus is the sync health of one source.
+type SyncStatus struct {
+	Source              string    `json:"source"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccessAt       time.Time `json:"last_success_at,omitzero"`
+	LastFailureAt       time.Time `json:"last_failure_at,omitzero"`
+	LastRunID           string    `json:"last_run_id,omitempty"`
+	// NextRetryAt is set while a failed sync waits for its backoff.
+	NextRetryAt time.Time `json:"-"`
+	// Alert is raised by a permanent failure. Scheduled syncs of the source
+	// stop until a sync succeeds again.
+	Alert bool `json:"alert,omitempty"`
+}
+
+// SyncDiff is what a full sync of a source would change in the store.
+type SyncDiff struct {
+	Source  string       `json:"source"`
+	Stats   SyncStats    `json:"stats"`
+	Changes []UserChange `json:"changes,omitempty"`
+}
+
+// UserChange describes how a sync would change one stored user. Fields names
+// the changed fields of an update, never their values, so that a diff can be
+// shared without exposing PII.
+type UserChange struct {
+	UserHash string       `json:"user_hash"`
+	Action   ChangeAction `json:"action"`
+	Fields   []string     `json:"fields,omitempty"`
+}
+
+type ChangeAction int
+
+const (
+	ChangeActionUnspecified ChangeAction = iota
+	ChangeActionCreate
+	ChangeActionUpdate
+	ChangeActionDelete
+)
+
+func (a ChangeAction) String() string {
+	switch a {
+	case ChangeActionCreate:
+		return "create"
+	case ChangeActionUpdate:
+		return "update"
+	case ChangeActionDelete:
+		return "delete"
+	default:
+		return "unspecified"
+	}
+}
+
+func (a ChangeAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *ChangeAction) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "create":
+		*a = ChangeActionCreate
+	case "update":
+		*a = ChangeActionUpdate
+	case "delete":
+		*a = ChangeActionDelete
+	default:
+		*a = ChangeActionUnspecified
+	}
+	return nil
+}
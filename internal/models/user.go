@@ -1,26 +1,123 @@
 package models
 
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
 type User struct {
-	UserHash string               `json:"user_hash"`
-	Status   UserStatus           `json:"status"`
-	IdpType  IdentityProviderType `json:"idp_type"`
-	PII      *UserPII             `json:"pii,omitempty"`
+	UserHash    string               `json:"user_hash"`
+	Status      UserStatus           `json:"status"`
+	IdpType     IdentityProviderType `json:"idp_type"`
+	Source      string               `json:"source"`
+	AccountType AccountType          `json:"account_type"`
+	PII         *UserPII             `json:"pii,omitempty"`
+
+	// Security signals below identify no one and are returned without PII.
+	// Times are in UTC; nil means the provider does not report them.
+	LastLogonAt       *time.Time `json:"last_logon_at,omitempty"`
+	PasswordLastSetAt *time.Time `json:"password_last_set_at,omitempty"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	Privileged        bool       `json:"privileged,omitempty"`
+	ServiceAccount    bool       `json:"service_account,omitempty"`
+	MFAEnabled        *bool      `json:"mfa_enabled,omitempty"`
+}
+
+// Fingerprint hashes the canonical content of the user. Attributes are
+// sorted first, so that providers returning them in a different order do not
+// change it.
+func (u User) Fingerprint() string {
+	if u.PII != nil {
+		pii := *u.PII
+		pii.Attributes = slices.SortedFunc(slices.Values(pii.Attributes), func(a, b Attribute) int {
+			return cmp.Or(cmp.Compare(a.Key, b.Key), strings.Compare(a.Value, b.Value))
+		})
+		u.PII = &pii
+	}
+
+	// Marshalling plain data cannot fail.
+	data, _ := json.Marshal(u)
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// UserFingerprint is the compact index entry of a stored user, which lets
+// syncs find changed users without loading full records.
+type UserFingerprint struct {
+	UserHash    string `json:"user_hash"`
+	Source      string `json:"source"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// UserRef locates a stored user in the identity provider it was read from.
+type UserRef struct {
+	Source   string `json:"source"`
+	SourceID string `json:"source_id"`
 }
 
 type UserStatus int
 
+// When several states apply, adapters report the first of Disabled, Expired,
+// Pending, Locked and PasswordExpired.
 const (
 	UserStatusUnspecified UserStatus = iota
 	UserStatusActive
 	UserStatusDisabled
+	UserStatusLocked
+	UserStatusExpired         // the account's expiry date has passed
+	UserStatusPasswordExpired // the password must be changed before signing in
+	UserStatusPending         // the account is not valid yet, e.g. a future hire
+	UserStatusDeleted
+)
+
+// AccountType is assigned during sync by the configured classification rules.
+type AccountType int
+
+const (
+	AccountTypeUnspecified AccountType = iota
+	AccountTypeHuman
+	AccountTypeService
+	AccountTypeShared
+	AccountTypePrivileged
+)
+
+// AccountTypeMatch names the condition of an AccountTypeRule.
+type AccountTypeMatch string
+
+const (
+	AccountTypeMatchOU           AccountTypeMatch = "ou"   // glob over the distinguished name
+	AccountTypeMatchName         AccountTypeMatch = "name" // regexp over username, display name or email
+	AccountTypeMatchNoEmployeeID AccountTypeMatch = "no_employee_id"
+	AccountTypeMatchPrivileged   AccountTypeMatch = "privileged" // adminCount, isAdmin
+	AccountTypeMatchSPN          AccountTypeMatch = "spn"        // servicePrincipalName present
 )
 
+// AccountTypeRule assigns Type to users matching the condition. OU patterns
+// are case-insensitive globs where "*" matches any run of characters; name
+// patterns are regular expressions. Pattern is only used by those two
+// matches.
+type AccountTypeRule struct {
+	Type    AccountType
+	Match   AccountTypeMatch
+	Pattern string
+}
+
 type IdentityProviderType int
 
 const (
 	IdentityProviderTypeUnspecified IdentityProviderType = iota
 	IdentityProviderTypeActiveDirectory
 	IdentityProviderTypeLDAP
+	IdentityProviderTypeSCIM
+	IdentityProviderTypeEntraID
+	IdentityProviderTypeGoogleWorkspace
+	IdentityProviderTypeFile
+	IdentityProviderTypeHR
 )
 
 type UserPII struct {
@@ -48,8 +145,47 @@ type AttributeKey int
 
 const (
 	AttributeKeyUnspecified AttributeKey = iota
+	AttributeKeyDistinguishedName
+)
+
+// UserChanges is an incremental change set reported by an identity provider
+// that supports delta queries.
+type UserChanges struct {
+	Changed    []User
+	RemovedIDs []string
+}
+
+// Group is a directory group. Like users, everything that identifies the
+// group or its members by name is kept in PII.
+type Group struct {
+	GroupHash    string    `json:"group_hash"`
+	Source       string    `json:"source"`
+	MemberHashes []string  `json:"member_hashes,omitempty"`
+	PII          *GroupPII `json:"pii,omitempty"`
+}
+
+type GroupPII struct {
+	SourceID    string   `json:"source_id,omitempty"`
+	DisplayName string   `json:"display_name,omitempty"`
+	MemberIDs   []string `json:"member_ids,omitempty"`
+}
+
+// Person groups the accounts of one human across identity providers.
+type Person struct {
+	PersonHash string   `json:"person_hash"`
+	UserHashes []string `json:"user_hashes"`
+	Accounts   []User   `json:"-"`
+}
+
+// CorrelationKey names a user field used to link accounts into a person.
+type CorrelationKey string
+
+const (
+	CorrelationKeyEmployeeID CorrelationKey = "employee_id"
+	CorrelationKeyEmail      CorrelationKey = "email"
 )
 
 type Filter struct {
-	Usernames []string `json:"usernames,omitempty"`
+	Usernames    []string      `json:"usernames,omitempty"`
+	AccountTypes []AccountType `json:"account_types,omitempty"`
 }
@@ -0,0 +1,339 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyPatch applies RFC 7644 PATCH operations to resource. The resource is
+// edited as generic JSON, so any attribute of its schema can be addressed.
+// Supported paths are "attr", "attr.sub", "attr[filter]" and
+// "attr[filter].sub", optionally prefixed with a schema URN, where filter is
+// a single "attr eq value" comparison.
+func ApplyPatch[T any](resource *T, ops []PatchOperation) error {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	doc := map[string]any{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		if err := applyOperation(doc, op); err != nil {
+			return err
+		}
+	}
+
+	// Some providers send booleans as strings, e.g. {"active": "False"}.
+	if v, ok := doc["active"].(string); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid value for active: %q", v)
+		}
+		doc["active"] = b
+	}
+
+	// Entra ID sends the manager as its bare ID rather than a complex value.
+	if ext, ok := lookup(doc, SchemaEnterpriseUser).(map[string]any); ok {
+		if v, ok := lookup(ext, "manager").(string); ok {
+			set(ext, "manager", map[string]any{"value": v})
+		}
+	}
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var patched T
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return fmt.Errorf("patched resource is invalid: %w", err)
+	}
+
+	*resource = patched
+	return nil
+}
+
+func applyOperation(doc map[string]any, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	switch kind {
+	case "add", "replace", "remove":
+	default:
+		return fmt.Errorf("unsupported patch op: %q", op.Op)
+	}
+
+	var value any
+	if len(op.Value) > 0 {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return fmt.Errorf("invalid patch value: %w", err)
+		}
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return fmt.Errorf("remove requires a path")
+		}
+
+		attrs, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s without path requires an object value", op.Op)
+		}
+
+		for key, v := range attrs {
+			p, err := parsePath(key)
+			if err != nil {
+				return err
+			}
+			if err := p.apply(doc, kind, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p, err := parsePath(op.Path)
+	if err != nil {
+		return err
+	}
+
+	return p.apply(doc, kind, value)
+}
+
+type path struct {
+	schema      string
+	attr        string
+	filterAttr  string
+	filterValue string
+	subAttr     string
+}
+
+func parsePath(raw string) (path, error) {
+	var p path
+
+	if strings.EqualFold(raw, SchemaEnterpriseUser) {
+		return path{attr: SchemaEnterpriseUser}, nil
+	}
+
+	rest := raw
+	for _, urn := range []string{SchemaEnterpriseUser, SchemaUser, SchemaGroup} {
+		if len(rest) > len(urn) && strings.EqualFold(rest[:len(urn)+1], urn+":") {
+			if urn == SchemaEnterpriseUser {
+				p.schema = urn
+			}
+			rest = rest[len(urn)+1:]
+			break
+		}
+	}
+
+	if open := strings.IndexByte(rest, '['); open >= 0 {
+		closing := strings.IndexByte(rest, ']')
+		if closing < open {
+			return path{}, fmt.Errorf("invalid path: %q", raw)
+		}
+
+		attr, expr, ok := strings.Cut(strings.TrimSpace(rest[open+1:closing]), " ")
+		op, value, ok2 := strings.Cut(strings.TrimSpace(expr), " ")
+		if !ok || !ok2 || !strings.EqualFold(op, "eq") {
+			return path{}, fmt.Errorf("unsupported filter in path: %q", raw)
+		}
+
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+
+		p.attr = rest[:open]
+		p.filterAttr = attr
+		p.filterValue = value
+		rest = strings.TrimPrefix(rest[closing+1:], ".")
+		p.subAttr = rest
+	} else {
+		p.attr, p.subAttr, _ = strings.Cut(rest, ".")
+	}
+
+	if p.attr == "" {
+		return path{}, fmt.Errorf("invalid path: %q", raw)
+	}
+
+	return p, nil
+}
+
+func (p path) apply(doc map[string]any, op string, value any) error {
+	container := doc
+	if p.schema != "" {
+		ext, ok := lookup(doc, p.schema).(map[string]any)
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			ext = map[string]any{}
+		}
+		set(doc, p.schema, ext)
+		container = ext
+	}
+
+	if p.filterAttr != "" {
+		return p.applyFiltered(container, op, value)
+	}
+
+	if p.subAttr != "" {
+		parent, ok := lookup(container, p.attr).(map[string]any)
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			parent = map[string]any{}
+		}
+		set(container, p.attr, parent)
+		container = parent
+		return applyAttr(container, p.subAttr, op, value)
+	}
+
+	return applyAttr(container, p.attr, op, value)
+}
+
+func (p path) applyFiltered(container map[string]any, op string, value any) error {
+	items, _ := lookup(container, p.attr).([]any)
+
+	kept := items[:0:0]
+	matched := false
+	for _, item := range items {
+		element, ok := item.(map[string]any)
+		// Filters address sub-attributes such as type and value, which are
+		// not case-exact.
+		if !ok || !strings.EqualFold(fmt.Sprint(lookup(element, p.filterAttr)), p.filterValue) {
+			kept = append(kept, item)
+			continue
+		}
+
+		matched = true
+		switch {
+		case op == "remove" && p.subAttr == "":
+			continue
+		case p.subAttr == "":
+			if v, ok := value.(map[string]any); ok {
+				for k, val := range v {
+					set(element, k, val)
+				}
+			}
+		default:
+			if err := applyAttr(element, p.subAttr, op, value); err != nil {
+				return err
+			}
+		}
+		kept = append(kept, element)
+	}
+
+	if !matched && op != "remove" {
+		element := map[string]any{p.filterAttr: p.filterValue}
+		if p.subAttr != "" {
+			element[p.subAttr] = value
+		} else if v, ok := value.(map[string]any); ok {
+			for k, val := range v {
+				element[k] = val
+			}
+		}
+		kept = append(kept, element)
+	}
+
+	set(container, p.attr, kept)
+	return nil
+}
+
+func applyAttr(container map[string]any, attr, op string, value any) error {
+	switch op {
+	case "remove":
+		// Entra ID removes group members by listing them in the value.
+		existing, isList := lookup(container, attr).([]any)
+		if values, ok := value.([]any); ok && isList {
+			set(container, attr, removeValues(existing, values))
+			return nil
+		}
+		if key, ok := findKey(container, attr); ok {
+			delete(container, key)
+		}
+	case "add":
+		switch existing := lookup(container, attr).(type) {
+		case []any:
+			if values, ok := value.([]any); ok {
+				set(container, attr, append(existing, values...))
+				return nil
+			}
+		case map[string]any:
+			if values, ok := value.(map[string]any); ok {
+				for k, v := range values {
+					set(existing, k, v)
+				}
+				return nil
+			}
+		}
+		set(container, attr, value)
+	case "replace":
+		set(container, attr, value)
+	}
+	return nil
+}
+
+// SCIM attribute names are case-insensitive.
+func findKey(m map[string]any, attr string) (string, bool) {
+	if _, ok := m[attr]; ok {
+		return attr, true
+	}
+	for key := range m {
+		if strings.EqualFold(key, attr) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func lookup(m map[string]any, attr string) any {
+	if key, ok := findKey(m, attr); ok {
+		return m[key]
+	}
+	return nil
+}
+
+func set(m map[string]any, attr string, value any) {
+	if key, ok := findKey(m, attr); ok {
+		m[key] = value
+		return
+	}
+	m[attr] = value
+}
+
+func removeValues(items, remove []any) []any {
+	drop := make(map[string]struct{}, len(remove))
+	for _, r := range remove {
+		if m, ok := r.(map[string]any); ok {
+			drop[fmt.Sprint(lookup(m, "value"))] = struct{}{}
+		}
+	}
+
+	kept := items[:0:0]
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			if _, found := drop[fmt.Sprint(lookup(m, "value"))]; found {
+				continue
+			}
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
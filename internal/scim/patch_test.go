@@ -0,0 +1,100 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func applyJSON(t *testing.T, resource *User, ops string) {
+	t.Helper()
+
+	var req PatchRequest
+	if err := json.Unmarshal([]byte(ops), &req); err != nil {
+		t.Fatalf("unmarshal patch: %v", err)
+	}
+	if err := ApplyPatch(resource, req.Operations); err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+}
+
+func TestApplyPatchManagerID(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ops  string
+	}{
+		{
+			name: "path",
+			ops: `{"Operations": [{"op": "Add",
+				"path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager",
+				"value": "26118915"}]}`,
+		},
+		{
+			name: "no path",
+			ops: `{"Operations": [{"op": "Replace",
+				"value": {"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager": "26118915"}}]}`,
+		},
+		{
+			name: "complex value",
+			ops: `{"Operations": [{"op": "replace",
+				"path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager",
+				"value": {"value": "26118915"}}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resource := User{UserName: "bjensen@example.com"}
+			applyJSON(t, &resource, tc.ops)
+
+			if resource.Enterprise == nil || resource.Enterprise.Manager == nil {
+				t.Fatal("manager not set")
+			}
+			if got := resource.Enterprise.Manager.Value; got != "26118915" {
+				t.Errorf("manager = %q, want 26118915", got)
+			}
+		})
+	}
+}
+
+func TestApplyPatchFilter(t *testing.T) {
+	resource := User{
+		UserName: "bjensen@example.com",
+		Emails: []MultiValue{
+			{Value: "babs@jensen.org", Type: "home"},
+			{Value: "bjensen@example.com", Type: "work email"},
+		},
+	}
+
+	applyJSON(t, &resource, `{"Operations": [
+		{"op": "replace", "path": "emails[type eq \"Work Email\"].value", "value": "barbara@example.com"},
+		{"op": "remove", "path": "emails[type eq \"HOME\"]"}
+	]}`)
+
+	if len(resource.Emails) != 1 {
+		t.Fatalf("emails = %+v, want one", resource.Emails)
+	}
+	if got := resource.Emails[0]; got.Value != "barbara@example.com" || got.Type != "work email" {
+		t.Errorf("email = %+v, want the replaced work email", got)
+	}
+}
+
+func TestApplyPatchActiveString(t *testing.T) {
+	active := true
+	resource := User{UserName: "bjensen@example.com", Active: &active}
+
+	applyJSON(t, &resource, `{"Operations": [{"op": "Replace", "path": "active", "value": "False"}]}`)
+
+	if resource.Active == nil || *resource.Active {
+		t.Errorf("active = %v, want false", resource.Active)
+	}
+}
+
+func TestParsePathUnsupportedFilter(t *testing.T) {
+	for _, raw := range []string{
+		`emails[type ne "work"]`,
+		`emails[type]`,
+		`emails]type eq "work"[`,
+	} {
+		if _, err := parsePath(raw); err == nil {
+			t.Errorf("parsePath(%q) succeeded", raw)
+		}
+	}
+}
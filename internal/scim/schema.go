@@ -0,0 +1,240 @@
+package scim
+
+import (
+	"desa-agent/internal/models"
+)
+
+// Schema URNs from RFC 7643 and RFC 7644.
+const (
+	SchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaPatchOp        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaSPConfig       = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// ContentType is the media type of SCIM request and response bodies.
+const ContentType = "application/scim+json"
+
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+type User struct {
+	Schemas      []string        `json:"schemas,omitempty"`
+	ID           string          `json:"id,omitempty"`
+	ExternalID   string          `json:"externalId,omitempty"`
+	UserName     string          `json:"userName"`
+	Name         *Name           `json:"name,omitempty"`
+	DisplayName  string          `json:"displayName,omitempty"`
+	Title        string          `json:"title,omitempty"`
+	Active       *bool           `json:"active,omitempty"`
+	Emails       []MultiValue    `json:"emails,omitempty"`
+	PhoneNumbers []MultiValue    `json:"phoneNumbers,omitempty"`
+	Addresses    []Address       `json:"addresses,omitempty"`
+	Enterprise   *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta         *Meta           `json:"meta,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type MultiValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type Address struct {
+	Formatted string `json:"formatted,omitempty"`
+	Locality  string `json:"locality,omitempty"`
+	Region    string `json:"region,omitempty"`
+	Country   string `json:"country,omitempty"`
+	Primary   bool   `json:"primary,omitempty"`
+}
+
+type EnterpriseUser struct {
+	EmployeeNumber string   `json:"employeeNumber,omitempty"`
+	Department     string   `json:"department,omitempty"`
+	Organization   string   `json:"organization,omitempty"`
+	Manager        *Manager `json:"manager,omitempty"`
+}
+
+type Manager struct {
+	Value string `json:"value,omitempty"`
+}
+
+type Group struct {
+	Schemas     []string `json:"schemas,omitempty"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members,omitempty"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string `json:"resourceType,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// ToUser maps the core User and Enterprise User schemas onto a models.User.
+// The SCIM id becomes the source ID.
+func ToUser(u User) models.User {
+	pii := &models.UserPII{
+		SourceID:    u.ID,
+		Username:    u.UserName,
+		DisplayName: u.DisplayName,
+		Title:       u.Title,
+		Email:       primaryValue(u.Emails),
+		Phone:       primaryValue(u.PhoneNumbers),
+		Location:    primaryLocation(u.Addresses),
+	}
+
+	if u.Name != nil {
+		pii.FirstName = u.Name.GivenName
+		pii.LastName = u.Name.FamilyName
+		if pii.DisplayName == "" {
+			pii.DisplayName = u.Name.Formatted
+		}
+	}
+
+	if u.Enterprise != nil {
+		pii.EmployeeID = u.Enterprise.EmployeeNumber
+		pii.Department = u.Enterprise.Department
+		if u.Enterprise.Manager != nil {
+			pii.ManagerID = u.Enterprise.Manager.Value
+		}
+	}
+
+	status := models.UserStatusActive
+	if u.Active != nil && !*u.Active {
+		status = models.UserStatusDisabled
+	}
+
+	return models.User{
+		Status:  status,
+		IdpType: models.IdentityProviderTypeSCIM,
+		PII:     pii,
+	}
+}
+
+// FromUser renders a stored user as a SCIM resource. Only attributes kept in
+// models.UserPII survive the round trip.
+func FromUser(u models.User) User {
+	active := u.Status == models.UserStatusActive
+	resource := User{
+		Schemas: []string{SchemaUser, SchemaEnterpriseUser},
+		Active:  &active,
+	}
+
+	pii := u.PII
+	if pii == nil {
+		return resource
+	}
+
+	resource.ID = pii.SourceID
+	resource.UserName = pii.Username
+	resource.DisplayName = pii.DisplayName
+	resource.Title = pii.Title
+
+	if pii.FirstName != "" || pii.LastName != "" {
+		resource.Name = &Name{GivenName: pii.FirstName, FamilyName: pii.LastName}
+	}
+	if pii.Email != "" {
+		resource.Emails = []MultiValue{{Value: pii.Email, Type: "work", Primary: true}}
+	}
+	if pii.Phone != "" {
+		resource.PhoneNumbers = []MultiValue{{Value: pii.Phone, Type: "work", Primary: true}}
+	}
+	if pii.Location != "" {
+		resource.Addresses = []Address{{Locality: pii.Location, Primary: true}}
+	}
+	if pii.EmployeeID != "" || pii.Department != "" || pii.ManagerID != "" {
+		resource.Enterprise = &EnterpriseUser{
+			EmployeeNumber: pii.EmployeeID,
+			Department:     pii.Department,
+		}
+		if pii.ManagerID != "" {
+			resource.Enterprise.Manager = &Manager{Value: pii.ManagerID}
+		}
+	}
+
+	return resource
+}
+
+// ToGroup maps a SCIM group onto a models.Group. Member hashes are left to
+// the caller, which knows the source the member IDs belong to.
+func ToGroup(g Group) models.Group {
+	pii := &models.GroupPII{
+		SourceID:    g.ID,
+		DisplayName: g.DisplayName,
+	}
+	for _, m := range g.Members {
+		pii.MemberIDs = append(pii.MemberIDs, m.Value)
+	}
+
+	return models.Group{PII: pii}
+}
+
+func FromGroup(g models.Group) Group {
+	resource := Group{Schemas: []string{SchemaGroup}}
+
+	if g.PII == nil {
+		return resource
+	}
+
+	resource.ID = g.PII.SourceID
+	resource.DisplayName = g.PII.DisplayName
+	for _, id := range g.PII.MemberIDs {
+		resource.Members = append(resource.Members, Member{Value: id})
+	}
+
+	return resource
+}
+
+func primaryValue(values []MultiValue) string {
+	for _, v := range values {
+		if v.Primary {
+			return v.Value
+		}
+	}
+	if len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
+
+func primaryLocation(addresses []Address) string {
+	for _, a := range addresses {
+		if a.Primary {
+			return addressLocation(a)
+		}
+	}
+	if len(addresses) > 0 {
+		return addressLocation(addresses[0])
+	}
+	return ""
+}
+
+func addressLocation(a Address) string {
+	if a.Locality != "" {
+		return a.Locality
+	}
+	return a.Formatted
+}
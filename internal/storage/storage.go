@@ -1,24 +1,50 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"desa-agent/internal/models"
 	"github.com/dgraph-io/badger/v4"
 )
 
-const userKeyPrefix = "user:"
+// upsertBatchSize is the number of users written per transaction.
+const upsertBatchSize = 1000
+
+const (
+	userKeyPrefix       = "user:"
+	personKeyPrefix     = "person:"
+	userPersonKeyPrefix = "user_person:"
+	userRefKeyPrefix    = "user_ref:"
+	userFPKeyPrefix     = "user_fp:"
+	userVersionPrefix   = "user_version:"
+
+	userHistorySinceKey = "meta:user_history_since"
+	groupKeyPrefix      = "group:"
+	syncRunKeyPrefix    = "sync_run:"
+	syncStatusKeyPrefix = "sync_status:"
+)
 
 type Storage struct {
-	db *badger.DB
+	db  *badger.DB
+	cfg Config
 }
 
 type Config struct {
 	Path     string
 	InMemory bool
+	ReadOnly bool
+	// SyncRunRetention is how long sync runs are kept; zero keeps them
+	// forever.
+	SyncRunRetention time.Duration
+	// UserHistoryRetention is how long user versions are kept; zero keeps
+	// them forever.
+	UserHistoryRetention time.Duration
 }
 
 func New(cfg Config) (*Storage, error) {
@@ -27,13 +53,25 @@ func New(cfg Config) (*Storage, error) {
 	if cfg.InMemory {
 		opts = opts.WithInMemory(true)
 	}
+	if cfg.ReadOnly {
+		opts = opts.WithReadOnly(true)
+	}
 
 	db, err := badger.Open(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open badger db: %w", err)
 	}
 
-	return &Storage{db: db}, nil
+	s := &Storage{db: db, cfg: cfg}
+
+	if !cfg.ReadOnly {
+		if err := s.indexFingerprints(); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to index user fingerprints: %w", err)
+		}
+	}
+
+	return s, nil
 }
 
 func (s *Storage) Close() error {
@@ -66,85 +104,751 @@ func (s *Storage) GetUser(ctx context.Context, userHash string) (*models.User, e
 }
 
 func (s *Storage) ListUsers(ctx context.Context) (<-chan models.User, <-chan error) {
-	usersCh := make(chan models.User)
-	errCh := make(chan error, 1)
+	return listPrefix[models.User](ctx, s.db, userKeyPrefix)
+}
 
-	go func() {
-		defer close(usersCh)
-		defer close(errCh)
+// UpsertUsers writes users together with their index entries and versions.
+// Each user is written atomically with its versions, but large sets span
+// several transactions to stay below badger's transaction size limit.
+func (s *Storage) UpsertUsers(ctx context.Context, users []models.User, versions []models.UserVersion) error {
+	byUser := make(map[string][]models.UserVersion, len(versions))
+	for _, version := range versions {
+		byUser[version.UserHash] = append(byUser[version.UserHash], version)
+	}
 
-		err := s.db.View(func(txn *badger.Txn) error {
+	for batch := range slices.Chunk(users, upsertBatchSize) {
+		if err := s.upsertUsers(batch, byUser); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) upsertUsers(users []models.User, versions map[string][]models.UserVersion) error {
+	err := s.db.Update(func(txn *badger.Txn) error {
+		for _, user := range users {
+			data, err := json.Marshal(user)
+			if err != nil {
+				return fmt.Errorf("failed to marshal user %s: %w", user.UserHash, err)
+			}
+
+			if err := txn.Set([]byte(userKeyPrefix+user.UserHash), data); err != nil {
+				return fmt.Errorf("failed to set user %s: %w", user.UserHash, err)
+			}
+
+			fp, err := marshalFingerprint(user)
+			if err != nil {
+				return fmt.Errorf("failed to marshal user fingerprint %s: %w", user.UserHash, err)
+			}
+			if err := txn.Set([]byte(userFPKeyPrefix+user.UserHash), fp); err != nil {
+				return fmt.Errorf("failed to set user fingerprint %s: %w", user.UserHash, err)
+			}
+
+			if err := s.setUserVersions(txn, versions[user.UserHash]); err != nil {
+				return err
+			}
+
+			if user.PII == nil || user.PII.SourceID == "" {
+				continue
+			}
+			ref, err := json.Marshal(models.UserRef{Source: user.Source, SourceID: user.PII.SourceID})
+			if err != nil {
+				return fmt.Errorf("failed to marshal user ref %s: %w", user.UserHash, err)
+			}
+			if err := txn.Set([]byte(userRefKeyPrefix+user.UserHash), ref); err != nil {
+				return fmt.Errorf("failed to set user ref %s: %w", user.UserHash, err)
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert users: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveUser deletes a user and its index entries, writing versions in the
+// same transaction.
+func (s *Storage) RemoveUser(ctx context.Context, userHash string, versions []models.UserVersion) error {
+	err := s.db.Update(func(txn *badger.Txn) error {
+		for _, prefix := range []string{userKeyPrefix, userRefKeyPrefix, userFPKeyPrefix} {
+			if err := txn.Delete([]byte(prefix + userHash)); err != nil {
+				return err
+			}
+		}
+		return s.setUserVersions(txn, versions)
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove user: %w", err)
+	}
+
+	return nil
+}
+
+func marshalFingerprint(user models.User) ([]byte, error) {
+	return json.Marshal(models.UserFingerprint{
+		UserHash:    user.UserHash,
+		Source:      user.Source,
+		Fingerprint: user.Fingerprint(),
+	})
+}
+
+// GetUserFingerprint returns the fingerprint of a stored user, or nil if the
+// user is not stored.
+func (s *Storage) GetUserFingerprint(ctx context.Context, userHash string) (*models.UserFingerprint, error) {
+	var fp models.UserFingerprint
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(userFPKeyPrefix + userHash))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &fp)
+		})
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user fingerprint: %w", err)
+	}
+
+	return &fp, nil
+}
+
+// ListUserFingerprints streams the fingerprints of every stored user.
+func (s *Storage) ListUserFingerprints(ctx context.Context) (<-chan models.UserFingerprint, <-chan error) {
+	return listPrefix[models.UserFingerprint](ctx, s.db, userFPKeyPrefix)
+}
+
+// indexFingerprints writes the fingerprints missing for users stored before
+// they were kept.
+func (s *Storage) indexFingerprints() error {
+	indexed := make(map[string]struct{})
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(userFPKeyPrefix)
+		opts.PrefetchValues = false
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			indexed[string(it.Item().Key()[len(userFPKeyPrefix):])] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var missing []models.User
+	err = s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(userKeyPrefix)
+		opts.PrefetchValues = false
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			if _, ok := indexed[string(it.Item().Key()[len(userKeyPrefix):])]; ok {
+				continue
+			}
+
+			var user models.User
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &user)
+			})
+			if err != nil {
+				return err
+			}
+			missing = append(missing, user)
+		}
+		return nil
+	})
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, user := range missing {
+		data, err := marshalFingerprint(user)
+		if err != nil {
+			return err
+		}
+		if err := wb.Set([]byte(userFPKeyPrefix+user.UserHash), data); err != nil {
+			return err
+		}
+	}
+
+	return wb.Flush()
+}
+
+// GetUserRef returns where a stored user comes from, or nil if no reference
+// was written for it.
+func (s *Storage) GetUserRef(ctx context.Context, userHash string) (*models.UserRef, error) {
+	var ref models.UserRef
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(userRefKeyPrefix + userHash))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &ref)
+		})
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ref: %w", err)
+	}
+
+	return &ref, nil
+}
+
+func (s *Storage) GetPerson(ctx context.Context, personHash string) (*models.Person, error) {
+	var person models.Person
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(personKeyPrefix + personHash))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &person)
+		})
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	return &person, nil
+}
+
+// GetPersonHash returns the hash of the person a user is linked to, or an
+// empty string if the user has not been correlated yet.
+func (s *Storage) GetPersonHash(ctx context.Context, userHash string) (string, error) {
+	var personHash string
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(userPersonKeyPrefix + userHash))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			personHash = string(val)
+			return nil
+		})
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get person hash: %w", err)
+	}
+
+	return personHash, nil
+}
+
+func (s *Storage) ListPersons(ctx context.Context) (<-chan models.Person, <-chan error) {
+	return listPrefix[models.Person](ctx, s.db, personKeyPrefix)
+}
+
+// ReplacePersons makes persons the complete set of stored persons. Unchanged
+// records are left alone and persons that no longer exist are removed, so
+// readers never observe an empty set while correlation runs.
+func (s *Storage) ReplacePersons(ctx context.Context, persons []models.Person) error {
+	wanted := make(map[string][]byte, len(persons)*2)
+	for _, person := range persons {
+		data, err := json.Marshal(person)
+		if err != nil {
+			return fmt.Errorf("failed to marshal person %s: %w", person.PersonHash, err)
+		}
+
+		wanted[personKeyPrefix+person.PersonHash] = data
+		for _, userHash := range person.UserHashes {
+			wanted[userPersonKeyPrefix+userHash] = []byte(person.PersonHash)
+		}
+	}
+
+	var stale []string
+	err := s.db.View(func(txn *badger.Txn) error {
+		for _, prefix := range []string{personKeyPrefix, userPersonKeyPrefix} {
 			opts := badger.DefaultIteratorOptions
-			opts.Prefix = []byte(userKeyPrefix)
+			opts.Prefix = []byte(prefix)
 
 			it := txn.NewIterator(opts)
-			defer it.Close()
-
 			for it.Rewind(); it.Valid(); it.Next() {
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				default:
+				key := string(it.Item().Key())
+
+				data, ok := wanted[key]
+				if !ok {
+					stale = append(stale, key)
+					continue
 				}
 
-				var user models.User
 				err := it.Item().Value(func(val []byte) error {
-					return json.Unmarshal(val, &user)
+					if bytes.Equal(val, data) {
+						delete(wanted, key)
+					}
+					return nil
 				})
 				if err != nil {
+					it.Close()
 					return err
 				}
-
-				select {
-				case usersCh <- user:
-				case <-ctx.Done():
-					return ctx.Err()
-				}
 			}
-			return nil
-		})
+			it.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read persons: %w", err)
+	}
+
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for key, data := range wanted {
+		if err := wb.Set([]byte(key), data); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	for _, key := range stale {
+		if err := wb.Delete([]byte(key)); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
 
+	if err := wb.Flush(); err != nil {
+		return fmt.Errorf("failed to replace persons: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetGroup(ctx context.Context, groupHash string) (*models.Group, error) {
+	var group models.Group
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(groupKeyPrefix + groupHash))
 		if err != nil {
-			errCh <- err
+			return err
 		}
-	}()
 
-	return usersCh, errCh
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &group)
+		})
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	return &group, nil
 }
 
-func (s *Storage) UpsertUsers(ctx context.Context, users []models.User) error {
+func (s *Storage) ListGroups(ctx context.Context) (<-chan models.Group, <-chan error) {
+	return listPrefix[models.Group](ctx, s.db, groupKeyPrefix)
+}
+
+func (s *Storage) UpsertGroups(ctx context.Context, groups []models.Group) error {
 	err := s.db.Update(func(txn *badger.Txn) error {
-		for _, user := range users {
-			data, err := json.Marshal(user)
+		for _, group := range groups {
+			data, err := json.Marshal(group)
 			if err != nil {
-				return fmt.Errorf("failed to marshal user %s: %w", user.UserHash, err)
+				return fmt.Errorf("failed to marshal group %s: %w", group.GroupHash, err)
 			}
 
-			if err := txn.Set([]byte(userKeyPrefix+user.UserHash), data); err != nil {
-				return fmt.Errorf("failed to set user %s: %w", user.UserHash, err)
+			if err := txn.Set([]byte(groupKeyPrefix+group.GroupHash), data); err != nil {
+				return fmt.Errorf("failed to set group %s: %w", group.GroupHash, err)
 			}
 		}
 		return nil
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to upsert users: %w", err)
+		return fmt.Errorf("failed to upsert groups: %w", err)
 	}
 
 	return nil
 }
 
-func (s *Storage) RemoveUser(ctx context.Context, userHash string) error {
+func (s *Storage) RemoveGroup(ctx context.Context, groupHash string) error {
 	err := s.db.Update(func(txn *badger.Txn) error {
-		return txn.Delete([]byte(userKeyPrefix + userHash))
+		return txn.Delete([]byte(groupKeyPrefix + groupHash))
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to remove group: %w", err)
+	}
+
+	return nil
+}
+
+// PutSyncRun creates or replaces a sync run. Runs expire after the
+// configured retention.
+func (s *Storage) PutSyncRun(ctx context.Context, run models.SyncRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync run %s: %w", run.ID, err)
+	}
+
+	entry := badger.NewEntry([]byte(syncRunKeyPrefix+run.ID), data)
+	if s.cfg.SyncRunRetention > 0 {
+		entry = entry.WithTTL(s.cfg.SyncRunRetention)
+	}
+
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(entry)
+	}); err != nil {
+		return fmt.Errorf("failed to put sync run: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetSyncRun(ctx context.Context, id string) (*models.SyncRun, error) {
+	var run models.SyncRun
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(syncRunKeyPrefix + id))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &run)
+		})
 	})
 
 	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// ListSyncRuns returns up to limit runs started before the run with ID
+// before, newest first. An empty source matches every source and an empty
+// before starts from the newest run.
+func (s *Storage) ListSyncRuns(ctx context.Context, source, before string, limit int) ([]models.SyncRun, error) {
+	var runs []models.SyncRun
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(syncRunKeyPrefix)
+		opts.Reverse = true
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// Reverse iteration seeks to the last key not after the seek key.
+		seek := []byte(syncRunKeyPrefix + "\xff")
+		if before != "" {
+			seek = []byte(syncRunKeyPrefix + before)
+		}
+
+		for it.Seek(seek); it.Valid() && len(runs) < limit; it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if before != "" && string(it.Item().Key()) == syncRunKeyPrefix+before {
+				continue
+			}
+
+			var run models.SyncRun
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &run)
+			})
+			if err != nil {
+				return err
+			}
+
+			if source == "" || run.Source == source {
+				runs = append(runs, run)
+			}
+		}
 		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync runs: %w", err)
 	}
+
+	return runs, nil
+}
+
+// setUserVersions stores user versions in txn. Versions expire after the
+// configured retention.
+func (s *Storage) setUserVersions(txn *badger.Txn, versions []models.UserVersion) error {
+	for _, version := range versions {
+		data, err := json.Marshal(version)
+		if err != nil {
+			return fmt.Errorf("failed to marshal user version %s: %w", version.ID, err)
+		}
+
+		entry := badger.NewEntry(userVersionKey(version.UserHash, version.ID), data)
+		if s.cfg.UserHistoryRetention > 0 {
+			entry = entry.WithTTL(s.cfg.UserHistoryRetention)
+		}
+		if err := txn.SetEntry(entry); err != nil {
+			return fmt.Errorf("failed to set user version %s: %w", version.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// ListUserVersions returns up to limit versions of a user older than the
+// version with ID before, newest first. An empty before starts from the
+// newest version; when fields is not empty, only versions changing one of
+// them are returned.
+func (s *Storage) ListUserVersions(ctx context.Context, userHash, before string, fields []string, limit int) ([]models.UserVersion, error) {
+	var versions []models.UserVersion
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		prefix := userVersionKey(userHash, "")
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.Reverse = true
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		seek := append(slices.Clone(prefix), 0xff)
+		if before != "" {
+			seek = userVersionKey(userHash, before)
+		}
+
+		for it.Seek(seek); it.Valid() && len(versions) < limit; it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if before != "" && bytes.Equal(it.Item().Key(), seek) {
+				continue
+			}
+
+			var version models.UserVersion
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &version)
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(fields) == 0 || slices.ContainsFunc(version.Fields, func(f string) bool {
+				return slices.Contains(fields, f)
+			}) {
+				versions = append(versions, version)
+			}
+		}
+		return nil
+	})
+
 	if err != nil {
-		return fmt.Errorf("failed to remove user: %w", err)
+		return nil, fmt.Errorf("failed to list user versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// ListUserVersionsAfter returns the versions newer than the version with ID
+// after, oldest first per user. An empty userHash returns those of every
+// user.
+func (s *Storage) ListUserVersionsAfter(ctx context.Context, userHash, after string) ([]models.UserVersion, error) {
+	var versions []models.UserVersion
+
+	prefix := []byte(userVersionPrefix)
+	if userHash != "" {
+		prefix = userVersionKey(userHash, "")
+	}
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			key := it.Item().Key()
+			if string(key[bytes.LastIndexByte(key, ':')+1:]) <= after {
+				continue
+			}
+
+			var version models.UserVersion
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &version)
+			})
+			if err != nil {
+				return err
+			}
+			versions = append(versions, version)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// UserHistorySince returns when user history was last enabled, storing now
+// on the first call after it was off.
+func (s *Storage) UserHistorySince(ctx context.Context, now time.Time) (time.Time, error) {
+	var since time.Time
+
+	err := s.db.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(userHistorySinceKey))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			since = now
+			data, err := since.MarshalText()
+			if err != nil {
+				return err
+			}
+			return txn.Set([]byte(userHistorySinceKey), data)
+		}
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			return since.UnmarshalText(val)
+		})
+	})
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user history start: %w", err)
+	}
+
+	return since, nil
+}
+
+// ClearUserHistorySince forgets when user history was first recorded, so
+// that the next UserHistorySince starts it anew. It is called while history
+// is off, as changes made meanwhile are not recorded.
+func (s *Storage) ClearUserHistorySince(ctx context.Context) error {
+	err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(userHistorySinceKey))
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to clear user history start: %w", err)
 	}
 
 	return nil
 }
+
+func userVersionKey(userHash, id string) []byte {
+	return []byte(userVersionPrefix + userHash + ":" + id)
+}
+
+func (s *Storage) PutSyncStatus(ctx context.Context, status models.SyncStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync status %s: %w", status.Source, err)
+	}
+
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(syncStatusKeyPrefix+status.Source), data)
+	}); err != nil {
+		return fmt.Errorf("failed to put sync status: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListSyncStatuses(ctx context.Context) (<-chan models.SyncStatus, <-chan error) {
+	return listPrefix[models.SyncStatus](ctx, s.db, syncStatusKeyPrefix)
+}
+
+// listPrefix streams the JSON values stored under prefix.
+func listPrefix[T any](ctx context.Context, db *badger.DB, prefix string) (<-chan T, <-chan error) {
+	valuesCh := make(chan T)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(valuesCh)
+		defer close(errCh)
+
+		err := db.View(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = []byte(prefix)
+
+			it := txn.NewIterator(opts)
+			defer it.Close()
+
+			for it.Rewind(); it.Valid(); it.Next() {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				default:
+				}
+
+				var value T
+				err := it.Item().Value(func(val []byte) error {
+					return json.Unmarshal(val, &value)
+				})
+				if err != nil {
+					return err
+				}
+
+				select {
+				case valuesCh <- value:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+
+		if err != nil {
+			errCh <- err
+		}
+	}()
+
+	return valuesCh, errCh
+}
@@ -0,0 +1,218 @@
+package transport
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"desa-agent/internal/models"
+	"desa-agent/internal/usecase"
+	pb "desa-agent/pkg/admin"
+)
+
+const (
+	defaultSyncRunsPageSize = 50
+	maxSyncRunsPageSize     = 500
+
+	defaultPreviewChanges = 1000
+	maxPreviewChanges     = 10000
+)
+
+type AdminServiceServer struct {
+	pb.UnimplementedAdminServiceServer
+	uc *usecase.UsersUseCase
+}
+
+func NewAdminServiceServer(uc *usecase.UsersUseCase) *AdminServiceServer {
+	return &AdminServiceServer{uc: uc}
+}
+
+func (s *AdminServiceServer) Register(grpcServer *grpc.Server) {
+	pb.RegisterAdminServiceServer(grpcServer, s)
+}
+
+func (s *AdminServiceServer) GetSyncStatus(ctx context.Context, req *pb.GetSyncStatusRequest) (*pb.GetSyncStatusResponse, error) {
+	resp := &pb.GetSyncStatusResponse{}
+
+	for _, syncStatus := range s.uc.SyncStatuses() {
+		if req.Source != "" && syncStatus.Source != req.Source {
+			continue
+		}
+
+		protoStatus := toProtoSyncStatus(&syncStatus)
+		if syncStatus.LastRunID != "" {
+			run, err := s.uc.GetSyncRun(ctx, syncStatus.LastRunID)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to get sync run: %v", err)
+			}
+			protoStatus.LastRun = toProtoSyncRun(run)
+		}
+
+		resp.Sources = append(resp.Sources, protoStatus)
+	}
+
+	if req.Source != "" && len(resp.Sources) == 0 {
+		return nil, status.Error(codes.NotFound, "source not found")
+	}
+
+	return resp, nil
+}
+
+func (s *AdminServiceServer) ListSyncRuns(ctx context.Context, req *pb.ListSyncRunsRequest) (*pb.ListSyncRunsResponse, error) {
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultSyncRunsPageSize
+	case pageSize > maxSyncRunsPageSize:
+		pageSize = maxSyncRunsPageSize
+	}
+
+	runs, err := s.uc.ListSyncRuns(ctx, req.Source, req.PageToken, pageSize)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sync runs: %v", err)
+	}
+
+	resp := &pb.ListSyncRunsResponse{}
+	for i := range runs {
+		resp.Runs = append(resp.Runs, toProtoSyncRun(&runs[i]))
+	}
+
+	// The last run of a full page is where the next page starts.
+	if len(runs) == pageSize {
+		resp.NextPageToken = runs[len(runs)-1].ID
+	}
+
+	return resp, nil
+}
+
+func (s *AdminServiceServer) GetSyncRun(ctx context.Context, req *pb.GetSyncRunRequest) (*pb.SyncRun, error) {
+	if req.RunId == "" {
+		return nil, status.Error(codes.InvalidArgument, "run_id is required")
+	}
+
+	run, err := s.uc.GetSyncRun(ctx, req.RunId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get sync run: %v", err)
+	}
+
+	if run == nil {
+		return nil, status.Error(codes.NotFound, "sync run not found")
+	}
+
+	return toProtoSyncRun(run), nil
+}
+
+func (s *AdminServiceServer) TriggerSync(ctx context.Context, req *pb.TriggerSyncRequest) (*pb.SyncRun, error) {
+	if req.Source == "" {
+		return nil, status.Error(codes.InvalidArgument, "source is required")
+	}
+
+	mode := models.SyncModeIncremental
+	switch req.Mode {
+	case pb.SyncMode_SYNC_MODE_UNSPECIFIED, pb.SyncMode_SYNC_MODE_INCREMENTAL:
+	case pb.SyncMode_SYNC_MODE_FULL:
+		mode = models.SyncModeFull
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown mode: %v", req.Mode)
+	}
+
+	run, err := s.uc.TriggerSync(ctx, req.Source, mode)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to trigger sync: %v", err)
+	}
+
+	if run == nil {
+		return nil, status.Error(codes.NotFound, "source not found")
+	}
+
+	return toProtoSyncRun(run), nil
+}
+
+func (s *AdminServiceServer) PreviewSync(ctx context.Context, req *pb.PreviewSyncRequest) (*pb.SyncPreview, error) {
+	if req.Source == "" {
+		return nil, status.Error(codes.InvalidArgument, "source is required")
+	}
+
+	maxChanges := int(req.MaxChanges)
+	switch {
+	case maxChanges < 0:
+		return nil, status.Error(codes.InvalidArgument, "max_changes must not be negative")
+	case maxChanges == 0:
+		maxChanges = defaultPreviewChanges
+	case maxChanges > maxPreviewChanges:
+		maxChanges = maxPreviewChanges
+	}
+
+	diff, err := s.uc.PreviewSync(ctx, req.Source)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to preview sync: %v", err)
+	}
+
+	if diff == nil {
+		return nil, status.Error(codes.NotFound, "source not found")
+	}
+
+	resp := &pb.SyncPreview{
+		Source:    diff.Source,
+		Created:   int32(diff.Stats.Created),
+		Updated:   int32(diff.Stats.Updated),
+		Deleted:   int32(diff.Stats.Deleted),
+		Unchanged: int32(diff.Stats.Unchanged),
+		Truncated: len(diff.Changes) > maxChanges,
+	}
+	for _, change := range diff.Changes[:min(len(diff.Changes), maxChanges)] {
+		resp.Changes = append(resp.Changes, &pb.UserChange{
+			UserHash: change.UserHash,
+			Action:   pb.ChangeAction(change.Action),
+			Fields:   change.Fields,
+		})
+	}
+
+	return resp, nil
+}
+
+func toProtoSyncStatus(s *models.SyncStatus) *pb.SourceSyncStatus {
+	return &pb.SourceSyncStatus{
+		Source:              s.Source,
+		LastSuccessTime:     toProtoTime(s.LastSuccessAt),
+		LastFailureTime:     toProtoTime(s.LastFailureAt),
+		LastError:           s.LastError,
+		ConsecutiveFailures: int32(s.ConsecutiveFailures),
+		Alert:               s.Alert,
+		NextRetryTime:       toProtoTime(s.NextRetryAt),
+	}
+}
+
+func toProtoSyncRun(r *models.SyncRun) *pb.SyncRun {
+	if r == nil {
+		return nil
+	}
+
+	return &pb.SyncRun{
+		RunId:     r.ID,
+		Source:    r.Source,
+		Mode:      pb.SyncMode(r.Mode),
+		State:     pb.SyncRunState(r.State),
+		StartTime: toProtoTime(r.StartedAt),
+		EndTime:   toProtoTime(r.FinishedAt),
+		Error:     r.Error,
+		Created:   int32(r.Stats.Created),
+		Updated:   int32(r.Stats.Updated),
+		Deleted:   int32(r.Stats.Deleted),
+		Unchanged: int32(r.Stats.Unchanged),
+	}
+}
+
+// toProtoTime maps the zero time, meaning never, to an unset timestamp.
+func toProtoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,489 @@
+package scim
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"desa-agent/internal/models"
+	schema "desa-agent/internal/scim"
+	"desa-agent/internal/usecase"
+)
+
+const (
+	basePath        = "/scim/v2"
+	defaultPageSize = 100
+	maxPageSize     = 200
+)
+
+// Server is a SCIM 2.0 service provider that lets identity providers push
+// users and groups to the agent. Everything it receives is stored under a
+// single source name.
+type Server struct {
+	uc     *usecase.UsersUseCase
+	source string
+	token  string
+}
+
+func NewServer(uc *usecase.UsersUseCase, source, token string) *Server {
+	return &Server{uc: uc, source: source, token: token}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+basePath+"/ServiceProviderConfig", s.serviceProviderConfig)
+
+	mux.HandleFunc("GET "+basePath+"/Users", s.listUsers)
+	mux.HandleFunc("POST "+basePath+"/Users", s.createUser)
+	mux.HandleFunc("GET "+basePath+"/Users/{id}", s.getUser)
+	mux.HandleFunc("PUT "+basePath+"/Users/{id}", s.replaceUser)
+	mux.HandleFunc("PATCH "+basePath+"/Users/{id}", s.patchUser)
+	mux.HandleFunc("DELETE "+basePath+"/Users/{id}", s.deleteUser)
+
+	mux.HandleFunc("GET "+basePath+"/Groups", s.listGroups)
+	mux.HandleFunc("POST "+basePath+"/Groups", s.createGroup)
+	mux.HandleFunc("GET "+basePath+"/Groups/{id}", s.getGroup)
+	mux.HandleFunc("PUT "+basePath+"/Groups/{id}", s.replaceGroup)
+	mux.HandleFunc("PATCH "+basePath+"/Groups/{id}", s.patchGroup)
+	mux.HandleFunc("DELETE "+basePath+"/Groups/{id}", s.deleteGroup)
+
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "", "invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) serviceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schemas":        []string{schema.SchemaSPConfig},
+		"patch":          map[string]any{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": maxPageSize},
+		"changePassword": map[string]any{"supported": false},
+		"sort":           map[string]any{"supported": false},
+		"etag":           map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with a static bearer token",
+			"primary":     true,
+		}},
+		"meta": map[string]any{"resourceType": "ServiceProviderConfig"},
+	})
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	users, err := s.uc.ListSourceUsers(r.Context(), s.source)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+
+	resources := make([]schema.User, 0, len(users))
+	for _, user := range users {
+		resource := s.toResource(r, user)
+		if filter.matchUser(resource) {
+			resources = append(resources, resource)
+		}
+	}
+
+	writeList(w, r, resources)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var resource schema.User
+	if !decode(w, r, &resource) {
+		return
+	}
+
+	if resource.UserName == "" {
+		writeError(w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+
+	resource.ID = newID()
+	s.storeUser(w, r, resource, http.StatusCreated)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.toResource(r, *user))
+}
+
+func (s *Server) replaceUser(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.findUser(w, r); !ok {
+		return
+	}
+
+	var resource schema.User
+	if !decode(w, r, &resource) {
+		return
+	}
+
+	if resource.UserName == "" {
+		writeError(w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+
+	resource.ID = r.PathValue("id")
+	s.storeUser(w, r, resource, http.StatusOK)
+}
+
+func (s *Server) patchUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	var patch schema.PatchRequest
+	if !decode(w, r, &patch) {
+		return
+	}
+
+	resource := schema.FromUser(*user)
+	if err := schema.ApplyPatch(&resource, patch.Operations); err != nil {
+		writeError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	resource.ID = r.PathValue("id")
+	s.storeUser(w, r, resource, http.StatusOK)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	removed, err := s.uc.RemoveSourceUser(r.Context(), s.source, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+
+	if !removed {
+		writeError(w, http.StatusNotFound, "", "user not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) findUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, err := s.uc.GetSourceUser(r.Context(), s.source, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return nil, false
+	}
+
+	if user == nil {
+		writeError(w, http.StatusNotFound, "", "user not found")
+		return nil, false
+	}
+
+	return user, true
+}
+
+func (s *Server) storeUser(w http.ResponseWriter, r *http.Request, resource schema.User, status int) {
+	stored, err := s.uc.PushUser(r.Context(), s.source, schema.ToUser(resource))
+	if errors.Is(err, usecase.ErrUserNameTaken) {
+		writeError(w, http.StatusConflict, "uniqueness", "userName is already in use")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+
+	writeJSON(w, status, s.toResource(r, *stored))
+}
+
+func (s *Server) toResource(r *http.Request, user models.User) schema.User {
+	resource := schema.FromUser(user)
+	resource.Meta = &schema.Meta{
+		ResourceType: "User",
+		Location:     location(r, "Users", resource.ID),
+	}
+	return resource
+}
+
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	groups, err := s.uc.ListSourceGroups(r.Context(), s.source)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+
+	resources := make([]schema.Group, 0, len(groups))
+	for _, group := range groups {
+		resource := s.toGroupResource(r, group)
+		if filter.matchGroup(resource) {
+			resources = append(resources, resource)
+		}
+	}
+
+	writeList(w, r, resources)
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	var resource schema.Group
+	if !decode(w, r, &resource) {
+		return
+	}
+
+	if resource.DisplayName == "" {
+		writeError(w, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	resource.ID = newID()
+	s.storeGroup(w, r, resource, http.StatusCreated)
+}
+
+func (s *Server) getGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := s.findGroup(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.toGroupResource(r, *group))
+}
+
+func (s *Server) replaceGroup(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.findGroup(w, r); !ok {
+		return
+	}
+
+	var resource schema.Group
+	if !decode(w, r, &resource) {
+		return
+	}
+
+	resource.ID = r.PathValue("id")
+	s.storeGroup(w, r, resource, http.StatusOK)
+}
+
+func (s *Server) patchGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := s.findGroup(w, r)
+	if !ok {
+		return
+	}
+
+	var patch schema.PatchRequest
+	if !decode(w, r, &patch) {
+		return
+	}
+
+	resource := schema.FromGroup(*group)
+	if err := schema.ApplyPatch(&resource, patch.Operations); err != nil {
+		writeError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	resource.ID = r.PathValue("id")
+	s.storeGroup(w, r, resource, http.StatusOK)
+}
+
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	removed, err := s.uc.RemoveSourceGroup(r.Context(), s.source, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+
+	if !removed {
+		writeError(w, http.StatusNotFound, "", "group not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) findGroup(w http.ResponseWriter, r *http.Request) (*models.Group, bool) {
+	group, err := s.uc.GetSourceGroup(r.Context(), s.source, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return nil, false
+	}
+
+	if group == nil {
+		writeError(w, http.StatusNotFound, "", "group not found")
+		return nil, false
+	}
+
+	return group, true
+}
+
+func (s *Server) storeGroup(w http.ResponseWriter, r *http.Request, resource schema.Group, status int) {
+	stored, err := s.uc.PushGroup(r.Context(), s.source, schema.ToGroup(resource))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+
+	writeJSON(w, status, s.toGroupResource(r, *stored))
+}
+
+func (s *Server) toGroupResource(r *http.Request, group models.Group) schema.Group {
+	resource := schema.FromGroup(group)
+	resource.Meta = &schema.Meta{
+		ResourceType: "Group",
+		Location:     location(r, "Groups", resource.ID),
+	}
+	return resource
+}
+
+// filter is the single "attr eq value" comparison identity providers use to
+// look up existing resources before creating them.
+type filter struct {
+	attr  string
+	value string
+}
+
+func parseFilter(raw string) (*filter, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	attr, rest, ok := strings.Cut(strings.TrimSpace(raw), " ")
+	op, value, ok2 := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !ok2 || !strings.EqualFold(op, "eq") {
+		return nil, fmt.Errorf("only \"attribute eq value\" filters are supported")
+	}
+
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	return &filter{attr: attr, value: value}, nil
+}
+
+func (f *filter) matchUser(u schema.User) bool {
+	if f == nil {
+		return true
+	}
+
+	switch strings.ToLower(f.attr) {
+	case "username":
+		return strings.EqualFold(u.UserName, f.value)
+	case "id":
+		return u.ID == f.value
+	case "emails.value", "emails":
+		for _, email := range u.Emails {
+			if strings.EqualFold(email.Value, f.value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *filter) matchGroup(g schema.Group) bool {
+	if f == nil {
+		return true
+	}
+
+	switch strings.ToLower(f.attr) {
+	case "displayname":
+		return strings.EqualFold(g.DisplayName, f.value)
+	case "id":
+		return g.ID == f.value
+	}
+	return false
+}
+
+func writeList[T any](w http.ResponseWriter, r *http.Request, resources []T) {
+	query := r.URL.Query()
+
+	startIndex, err := strconv.Atoi(query.Get("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err := strconv.Atoi(query.Get("count"))
+	if err != nil || count < 0 {
+		count = defaultPageSize
+	}
+	count = min(count, maxPageSize)
+
+	from := min(startIndex-1, len(resources))
+	to := min(from+count, len(resources))
+
+	writeJSON(w, http.StatusOK, schema.ListResponse[T]{
+		Schemas:      []string{schema.SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: to - from,
+		Resources:    resources[from:to],
+	})
+}
+
+func decode(w http.ResponseWriter, r *http.Request, out any) bool {
+	if err := json.NewDecoder(r.Body).Decode(out); err != nil {
+		writeError(w, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", schema.ContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, scimType, detail string) {
+	body := map[string]any{
+		"schemas": []string{schema.SchemaError},
+		"status":  strconv.Itoa(status),
+		"detail":  detail,
+	}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	writeJSON(w, status, body)
+}
+
+func location(r *http.Request, resourceType, id string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s/%s/%s", scheme, r.Host, basePath, resourceType, id)
+}
+
+// newID returns a random version 4 UUID used as the SCIM id of new resources.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,132 @@
+package scim_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	schema "desa-agent/internal/scim"
+	"desa-agent/internal/storage"
+	scimtransport "desa-agent/internal/transport/scim"
+	"desa-agent/internal/usecase"
+)
+
+const token = "scim-server-token"
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	store, err := storage.New(storage.Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	classifier, err := usecase.NewClassifier(nil)
+	if err != nil {
+		t.Fatalf("usecase.NewClassifier: %v", err)
+	}
+	uc := usecase.NewUsersUseCase(store, nil, nil, classifier, usecase.UserHistory{})
+
+	server := httptest.NewServer(scimtransport.NewServer(uc, "scim", token).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends body to path and decodes the response into out unless it is nil.
+func do(t *testing.T, server *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+"/scim/v2"+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", schema.ContentType)
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s %s response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func createUser(t *testing.T, server *httptest.Server, userName string) schema.User {
+	t.Helper()
+
+	var created schema.User
+	status := do(t, server, http.MethodPost, "/Users", `{"userName": "`+userName+`"}`, &created)
+	if status != http.StatusCreated {
+		t.Fatalf("create %s: status %d", userName, status)
+	}
+	return created
+}
+
+func TestPatchUserEntraManager(t *testing.T) {
+	server := newServer(t)
+	created := createUser(t, server, "bjensen@example.com")
+
+	var patched schema.User
+	status := do(t, server, http.MethodPatch, "/Users/"+created.ID, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [{
+			"op": "Add",
+			"path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager",
+			"value": "26118915"
+		}]
+	}`, &patched)
+	if status != http.StatusOK {
+		t.Fatalf("patch: status %d", status)
+	}
+
+	var stored schema.User
+	if status := do(t, server, http.MethodGet, "/Users/"+created.ID, "", &stored); status != http.StatusOK {
+		t.Fatalf("get: status %d", status)
+	}
+	if stored.Enterprise == nil || stored.Enterprise.Manager == nil || stored.Enterprise.Manager.Value != "26118915" {
+		t.Errorf("stored enterprise extension = %+v, want manager 26118915", stored.Enterprise)
+	}
+}
+
+func TestUserNameUniqueness(t *testing.T) {
+	server := newServer(t)
+	createUser(t, server, "bjensen@example.com")
+	other := createUser(t, server, "jsmith@example.com")
+
+	var scimErr struct {
+		ScimType string `json:"scimType"`
+	}
+	status := do(t, server, http.MethodPost, "/Users", `{"userName": "BJensen@example.com"}`, &scimErr)
+	if status != http.StatusConflict || scimErr.ScimType != "uniqueness" {
+		t.Errorf("create duplicate: status %d, scimType %q, want 409 uniqueness", status, scimErr.ScimType)
+	}
+
+	status = do(t, server, http.MethodPatch, "/Users/"+other.ID,
+		`{"Operations": [{"op": "replace", "path": "userName", "value": "bjensen@example.com"}]}`, nil)
+	if status != http.StatusConflict {
+		t.Errorf("rename to a taken userName: status %d, want 409", status)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	server := newServer(t)
+
+	resp, err := server.Client().Get(server.URL + "/scim/v2/Users")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+}
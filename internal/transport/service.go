@@ -2,16 +2,24 @@ package transport
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"desa-agent/internal/models"
 	"desa-agent/internal/usecase"
 	pb "desa-agent/pkg/users"
 )
 
+const (
+	defaultHistoryPageSize = 100
+	maxHistoryPageSize     = 1000
+)
+
 type UsersServiceServer struct {
 	pb.UnimplementedUsersServiceServer
 	uc *usecase.UsersUseCase
@@ -30,9 +38,15 @@ func (s *UsersServiceServer) GetUser(ctx context.Context, req *pb.GetUserRequest
 		return nil, status.Error(codes.InvalidArgument, "user_hash is required")
 	}
 
-	user, err := s.uc.GetUser(ctx, req.UserHash, req.IncludePii)
+	var user *models.User
+	var err error
+	if req.AsOf != nil {
+		user, err = s.uc.GetUserAsOf(ctx, req.UserHash, req.IncludePii, req.AsOf.AsTime())
+	} else {
+		user, err = s.uc.GetUser(ctx, req.UserHash, req.IncludePii)
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		return nil, historyError(err, "failed to get user")
 	}
 
 	if user == nil {
@@ -42,10 +56,74 @@ func (s *UsersServiceServer) GetUser(ctx context.Context, req *pb.GetUserRequest
 	return toProtoUser(user), nil
 }
 
+func (s *UsersServiceServer) RefreshUser(ctx context.Context, req *pb.RefreshUserRequest) (*pb.User, error) {
+	if req.UserHash == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_hash is required")
+	}
+
+	user, err := s.uc.RefreshUser(ctx, req.UserHash, req.IncludePii)
+	if errors.Is(err, usecase.ErrNotRefreshable) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to refresh user: %v", err)
+	}
+
+	if user == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (s *UsersServiceServer) GetUserHistory(ctx context.Context, req *pb.GetUserHistoryRequest) (*pb.GetUserHistoryResponse, error) {
+	if req.UserHash == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_hash is required")
+	}
+
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultHistoryPageSize
+	case pageSize > maxHistoryPageSize:
+		pageSize = maxHistoryPageSize
+	}
+
+	versions, err := s.uc.GetUserHistory(ctx, req.UserHash, req.IncludePii, req.Fields, req.PageToken, pageSize)
+	if err != nil {
+		return nil, historyError(err, "failed to get user history")
+	}
+
+	resp := &pb.GetUserHistoryResponse{}
+	for i := range versions {
+		resp.Versions = append(resp.Versions, toProtoUserVersion(&versions[i]))
+	}
+
+	// The last version of a full page is where the next page starts.
+	if len(versions) == pageSize {
+		resp.NextPageToken = versions[len(versions)-1].ID
+	}
+
+	return resp, nil
+}
+
 func (s *UsersServiceServer) ListUsers(req *pb.ListUsersRequest, stream grpc.ServerStreamingServer[pb.User]) error {
 	ctx := stream.Context()
 
-	usersCh, errCh := s.uc.ListUsers(ctx, req.IncludePii)
+	filter := models.Filter{}
+	for _, accountType := range req.AccountTypes {
+		filter.AccountTypes = append(filter.AccountTypes, models.AccountType(accountType))
+	}
+
+	var usersCh <-chan models.User
+	var errCh <-chan error
+	if req.AsOf != nil {
+		usersCh, errCh = s.uc.ListUsersAsOf(ctx, req.IncludePii, filter, req.AsOf.AsTime())
+	} else {
+		usersCh, errCh = s.uc.ListUsers(ctx, req.IncludePii, filter)
+	}
 
 	for {
 		select {
@@ -54,7 +132,7 @@ func (s *UsersServiceServer) ListUsers(req *pb.ListUsersRequest, stream grpc.Ser
 
 		case err := <-errCh:
 			if err != nil {
-				return status.Errorf(codes.Internal, "failed to list users: %v", err)
+				return historyError(err, "failed to list users")
 			}
 
 		case user, ok := <-usersCh:
@@ -69,15 +147,98 @@ func (s *UsersServiceServer) ListUsers(req *pb.ListUsersRequest, stream grpc.Ser
 	}
 }
 
+func (s *UsersServiceServer) GetPerson(ctx context.Context, req *pb.GetPersonRequest) (*pb.Person, error) {
+	var (
+		person *models.Person
+		err    error
+	)
+
+	switch {
+	case req.GetPersonHash() != "":
+		person, err = s.uc.GetPerson(ctx, req.GetPersonHash(), req.IncludePii)
+	case req.GetUserHash() != "":
+		person, err = s.uc.GetPersonByUser(ctx, req.GetUserHash(), req.IncludePii)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "person_hash or user_hash is required")
+	}
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get person: %v", err)
+	}
+
+	if person == nil {
+		return nil, status.Error(codes.NotFound, "person not found")
+	}
+
+	return toProtoPerson(person), nil
+}
+
+func (s *UsersServiceServer) ListPersons(req *pb.ListPersonsRequest, stream grpc.ServerStreamingServer[pb.Person]) error {
+	ctx := stream.Context()
+
+	personsCh, errCh := s.uc.ListPersons(ctx, req.IncludePii)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.Error(codes.Canceled, "request canceled")
+
+		case err := <-errCh:
+			if err != nil {
+				return status.Errorf(codes.Internal, "failed to list persons: %v", err)
+			}
+
+		case person, ok := <-personsCh:
+			if !ok {
+				return nil
+			}
+
+			if err := stream.Send(toProtoPerson(&person)); err != nil {
+				return status.Errorf(codes.Internal, "failed to send person: %v", err)
+			}
+		}
+	}
+}
+
+// historyError maps errors of reading the user history to their status,
+// falling back to Internal with msg.
+func historyError(err error, msg string) error {
+	switch {
+	case errors.Is(err, usecase.ErrHistoryDisabled), errors.Is(err, usecase.ErrBeforeHistory):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrHistoryExpired):
+		return status.Error(codes.OutOfRange, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", msg, err)
+	}
+}
+
+func toProtoPerson(p *models.Person) *pb.Person {
+	protoPerson := &pb.Person{PersonHash: p.PersonHash}
+	for i := range p.Accounts {
+		protoPerson.Accounts = append(protoPerson.Accounts, toProtoUser(&p.Accounts[i]))
+	}
+	return protoPerson
+}
+
 func toProtoUser(u *models.User) *pb.User {
 	if u == nil {
 		return nil
 	}
 
 	protoUser := &pb.User{
-		UserHash: u.UserHash,
-		Status:   toProtoUserStatus(u.Status),
-		IdpType:  toProtoIdpType(u.IdpType),
+		UserHash:    u.UserHash,
+		Status:      toProtoUserStatus(u.Status),
+		IdpType:     toProtoIdpType(u.IdpType),
+		Source:      u.Source,
+		AccountType: pb.AccountType(u.AccountType),
+
+		LastLogonTime:       toProtoTimestamp(u.LastLogonAt),
+		PasswordLastSetTime: toProtoTimestamp(u.PasswordLastSetAt),
+		CreateTime:          toProtoTimestamp(u.CreatedAt),
+		Privileged:          u.Privileged,
+		ServiceAccount:      u.ServiceAccount,
+		MfaEnabled:          u.MFAEnabled,
 	}
 
 	if u.PII != nil {
@@ -87,6 +248,39 @@ func toProtoUser(u *models.User) *pb.User {
 	return protoUser
 }
 
+func toProtoUserVersion(v *models.UserVersion) *pb.UserVersion {
+	version := &pb.UserVersion{
+		ChangeTime: timestamppb.New(v.ChangedAt),
+		ChangeType: pb.UserChangeType(v.Action),
+		Fields:     v.Fields,
+	}
+
+	for _, change := range v.Changes {
+		version.Changes = append(version.Changes, &pb.FieldChange{
+			Field:    change.Field,
+			OldValue: toProtoRawValue(change.Old),
+			NewValue: toProtoRawValue(change.New),
+		})
+	}
+
+	return version
+}
+
+func toProtoRawValue(value []byte) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	s := string(value)
+	return &s
+}
+
+func toProtoTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
 func toProtoUserPII(pii *models.UserPII) *pb.UserPII {
 	if pii == nil {
 		return nil
@@ -144,6 +338,16 @@ func toProtoUserStatus(status models.UserStatus) pb.UserStatus {
 		return pb.UserStatus_USER_STATUS_ACTIVE
 	case models.UserStatusDisabled:
 		return pb.UserStatus_USER_STATUS_DISABLED
+	case models.UserStatusLocked:
+		return pb.UserStatus_USER_STATUS_LOCKED
+	case models.UserStatusExpired:
+		return pb.UserStatus_USER_STATUS_EXPIRED
+	case models.UserStatusPasswordExpired:
+		return pb.UserStatus_USER_STATUS_PASSWORD_EXPIRED
+	case models.UserStatusPending:
+		return pb.UserStatus_USER_STATUS_PENDING
+	case models.UserStatusDeleted:
+		return pb.UserStatus_USER_STATUS_DELETED
 	default:
 		return pb.UserStatus_USER_STATUS_UNSPECIFIED
 	}
@@ -155,6 +359,16 @@ func toProtoIdpType(idpType models.IdentityProviderType) pb.IdentityProviderType
 		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_ACTIVE_DIRECTORY
 	case models.IdentityProviderTypeLDAP:
 		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_LDAP
+	case models.IdentityProviderTypeSCIM:
+		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_SCIM
+	case models.IdentityProviderTypeEntraID:
+		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_ENTRA_ID
+	case models.IdentityProviderTypeGoogleWorkspace:
+		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_GOOGLE_WORKSPACE
+	case models.IdentityProviderTypeFile:
+		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_FILE
+	case models.IdentityProviderTypeHR:
+		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_HR
 	default:
 		return pb.IdentityProviderType_IDENTITY_PROVIDER_TYPE_UNSPECIFIED
 	}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"desa-agent/internal/models"
+)
+
+var (
+	// ErrBeforeHistory is returned for points in time before user history
+	// was last enabled. Changes made while it was off are not recorded.
+	ErrBeforeHistory = errors.New("point in time precedes the recorded user history")
+	// ErrHistoryExpired is returned for points in time whose versions have
+	// expired.
+	ErrHistoryExpired = errors.New("point in time is beyond the user history retention")
+)
+
+// GetUserAsOf returns a user as it was stored at asOf, or nil if it was not
+// stored then. The current record is rewound by undoing the versions
+// recorded since.
+func (uc *UsersUseCase) GetUserAsOf(ctx context.Context, userHash string, includePII bool, asOf time.Time) (*models.User, error) {
+	if err := uc.checkAsOf(asOf); err != nil {
+		return nil, err
+	}
+
+	current, err := uc.storage.GetUser(ctx, userHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	versions, err := uc.storage.ListUserVersionsAfter(ctx, userHash, versionID(asOf))
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListUserVersionsAfter: %w", err)
+	}
+
+	user, err := uc.rewindUser(current, versions)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	if !includePII {
+		user.PII = nil
+	}
+
+	return user, nil
+}
+
+// ListUsersAsOf streams the users stored at asOf that match filter, like
+// ListUsers does for the current ones.
+func (uc *UsersUseCase) ListUsersAsOf(ctx context.Context, includePII bool, filter models.Filter, asOf time.Time) (<-chan models.User, <-chan error) {
+	outCh := make(chan models.User)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(outCh)
+		defer close(errCh)
+
+		if err := uc.listUsersAsOf(ctx, includePII, filter, asOf, outCh); err != nil {
+			errCh <- err
+		}
+	}()
+
+	return outCh, errCh
+}
+
+func (uc *UsersUseCase) listUsersAsOf(ctx context.Context, includePII bool, filter models.Filter, asOf time.Time, outCh chan<- models.User) error {
+	if err := uc.checkAsOf(asOf); err != nil {
+		return err
+	}
+
+	versions, err := uc.storage.ListUserVersionsAfter(ctx, "", versionID(asOf))
+	if err != nil {
+		return fmt.Errorf("storage.ListUserVersionsAfter: %w", err)
+	}
+
+	changed := make(map[string][]models.UserVersion)
+	for _, version := range versions {
+		changed[version.UserHash] = append(changed[version.UserHash], version)
+	}
+
+	send := func(current *models.User, versions []models.UserVersion) error {
+		user, err := uc.rewindUser(current, versions)
+		if err != nil || user == nil || !matchesFilter(*user, filter) {
+			return err
+		}
+
+		if !includePII {
+			user.PII = nil
+		}
+
+		select {
+		case outCh <- *user:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	usersCh, storageErrCh := uc.storage.ListUsers(ctx)
+	for user := range usersCh {
+		if err := send(&user, changed[user.UserHash]); err != nil {
+			// Drain so that the storage goroutine can finish.
+			for range usersCh {
+			}
+			return err
+		}
+		delete(changed, user.UserHash)
+	}
+	if err := <-storageErrCh; err != nil {
+		return fmt.Errorf("storage error: %w", err)
+	}
+
+	// The remaining users were removed after asOf.
+	for _, userHash := range slices.Sorted(maps.Keys(changed)) {
+		if err := send(nil, changed[userHash]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkAsOf rejects points in time the user history cannot reconstruct.
+func (uc *UsersUseCase) checkAsOf(asOf time.Time) error {
+	if uc.history.Cipher == nil {
+		return ErrHistoryDisabled
+	}
+
+	if asOf.Before(uc.history.Since) {
+		return fmt.Errorf("%w, which starts at %s", ErrBeforeHistory, uc.history.Since.UTC().Format(time.RFC3339))
+	}
+
+	if uc.history.Retention > 0 {
+		if expired := time.Now().Add(-uc.history.Retention); asOf.Before(expired) {
+			return fmt.Errorf("%w, which reaches back to %s", ErrHistoryExpired, expired.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// rewindUser undoes versions, given oldest first, on the current record of a
+// user, which is nil when it is not stored.
+func (uc *UsersUseCase) rewindUser(current *models.User, versions []models.UserVersion) (*models.User, error) {
+	user := current
+	for _, version := range slices.Backward(versions) {
+		if err := uc.openVersion(&version); err != nil {
+			return nil, err
+		}
+
+		switch version.Action {
+		case models.ChangeActionCreate:
+			user = nil
+		case models.ChangeActionUpdate, models.ChangeActionDelete:
+			var base models.User
+			if user != nil && version.Action == models.ChangeActionUpdate {
+				base = *user
+			}
+
+			restored, err := restoreFields(base, version.Changes)
+			if err != nil {
+				return nil, fmt.Errorf("version %s of user %s: %w", version.ID, version.UserHash, err)
+			}
+			user = &restored
+		}
+	}
+
+	return user, nil
+}
+
+// restoreFields sets the changed fields of user back to their old values.
+func restoreFields(user models.User, changes []models.FieldChange) (models.User, error) {
+	values, err := fieldValues(user)
+	if err != nil {
+		return user, err
+	}
+
+	for _, change := range changes {
+		if len(change.Old) == 0 {
+			delete(values, change.Field)
+			continue
+		}
+		values[change.Field] = change.Old
+	}
+
+	// Put the PII fields back into their own object, as fieldValues found
+	// them.
+	pii := make(map[string]json.RawMessage)
+	for name, value := range values {
+		if piiName, ok := strings.CutPrefix(name, "pii."); ok {
+			pii[piiName] = value
+			delete(values, name)
+		}
+	}
+	if len(pii) > 0 {
+		data, err := json.Marshal(pii)
+		if err != nil {
+			return user, fmt.Errorf("marshal user pii fields: %w", err)
+		}
+		values["pii"] = data
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return user, fmt.Errorf("marshal user fields: %w", err)
+	}
+
+	var restored models.User
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return user, fmt.Errorf("unmarshal user fields: %w", err)
+	}
+	return restored, nil
+}
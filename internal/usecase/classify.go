@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"desa-agent/internal/models"
+)
+
+// Classifier assigns account types with the first matching rule. Users
+// matching no rule are human.
+type Classifier struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	models.AccountTypeRule
+	pattern *regexp.Regexp
+}
+
+func NewClassifier(rules []models.AccountTypeRule) (*Classifier, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c := compiledRule{AccountTypeRule: rule}
+
+		var err error
+		switch rule.Match {
+		case models.AccountTypeMatchOU:
+			c.pattern, err = regexp.Compile("(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(rule.Pattern), `\*`, ".*") + "$")
+		case models.AccountTypeMatchName:
+			c.pattern, err = regexp.Compile(rule.Pattern)
+		case models.AccountTypeMatchNoEmployeeID, models.AccountTypeMatchPrivileged, models.AccountTypeMatchSPN:
+		default:
+			err = fmt.Errorf("unknown match %q", rule.Match)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("account type rule %s=%s: %w", rule.Match, rule.Pattern, err)
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	return &Classifier{rules: compiled}, nil
+}
+
+func (c *Classifier) Classify(user *models.User) models.AccountType {
+	for _, rule := range c.rules {
+		if rule.matches(user) {
+			return rule.Type
+		}
+	}
+	return models.AccountTypeHuman
+}
+
+func (r compiledRule) matches(user *models.User) bool {
+	pii := user.PII
+	if pii == nil {
+		pii = &models.UserPII{}
+	}
+
+	switch r.Match {
+	case models.AccountTypeMatchOU:
+		for _, attr := range pii.Attributes {
+			if attr.Key == models.AttributeKeyDistinguishedName && r.pattern.MatchString(attr.Value) {
+				return true
+			}
+		}
+		return false
+	case models.AccountTypeMatchName:
+		for _, name := range []string{pii.Username, pii.DisplayName, pii.Email} {
+			if name != "" && r.pattern.MatchString(name) {
+				return true
+			}
+		}
+		return false
+	case models.AccountTypeMatchNoEmployeeID:
+		return pii.EmployeeID == ""
+	case models.AccountTypeMatchPrivileged:
+		return user.Privileged
+	case models.AccountTypeMatchSPN:
+		return user.ServiceAccount
+	default:
+		return false
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"desa-agent/internal/models"
+)
+
+// PreviewSync returns what a full sync of source would change in the store,
+// or nil when the source does not exist. Nothing is written. Previews always
+// compare the full source, as reading a delta would advance the provider's
+// cursor and hide those changes from the next sync.
+func (uc *UsersUseCase) PreviewSync(ctx context.Context, sourceName string) (*models.SyncDiff, error) {
+	source, ok := uc.sources[sourceName]
+	if !ok {
+		return nil, nil
+	}
+
+	unlock, err := uc.lockSource(ctx, source.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	plan, err := uc.planSync(ctx, source, true)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(plan.changes, func(a, b models.UserChange) int {
+		return cmp.Or(cmp.Compare(a.Action, b.Action), strings.Compare(a.UserHash, b.UserHash))
+	})
+
+	return &models.SyncDiff{
+		Source:  source.Name,
+		Stats:   plan.stats,
+		Changes: plan.changes,
+	}, nil
+}
+
+// describeUpdate names the fields of the stored user that user changes. The
+// stored record is only loaded for previews; syncs compare fingerprints.
+func (uc *UsersUseCase) describeUpdate(ctx context.Context, user models.User) (models.UserChange, error) {
+	change := models.UserChange{UserHash: user.UserHash, Action: models.ChangeActionUpdate}
+
+	stored, err := uc.storage.GetUser(ctx, user.UserHash)
+	if err != nil {
+		return change, fmt.Errorf("storage.GetUser: %w", err)
+	}
+	if stored != nil {
+		change.Fields = changedFields(*stored, user)
+	}
+	return change, nil
+}
+
+var userPIIType = reflect.TypeFor[*models.UserPII]()
+
+// changedFields names the fields that differ between two versions of a user
+// by their JSON names, with PII fields prefixed by "pii.". Attributes are
+// compared regardless of their order.
+func changedFields(old, new models.User) []string {
+	return appendChangedFields(nil, "", reflect.ValueOf(old), reflect.ValueOf(new))
+}
+
+func appendChangedFields(fields []string, prefix string, old, new reflect.Value) []string {
+	for i := range old.NumField() {
+		field := old.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		name = prefix + name
+
+		a, b := old.Field(i), new.Field(i)
+		switch {
+		case field.Type == userPIIType:
+			// A missing PII block counts as an empty one, so that its
+			// appearance lists the fields it brings.
+			if a.IsNil() && b.IsNil() {
+				continue
+			}
+			fields = appendChangedFields(fields, name+".", derefPII(a), derefPII(b))
+		case field.Name == "Attributes":
+			if !sameAttributes(a.Interface().([]models.Attribute), b.Interface().([]models.Attribute)) {
+				fields = append(fields, name)
+			}
+		case !reflect.DeepEqual(a.Interface(), b.Interface()):
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+func derefPII(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.ValueOf(models.UserPII{})
+	}
+	return v.Elem()
+}
+
+func sameAttributes(a, b []models.Attribute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	compare := func(x, y models.Attribute) int {
+		return cmp.Or(cmp.Compare(x.Key, y.Key), strings.Compare(x.Value, y.Value))
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.SortFunc(a, compare)
+	slices.SortFunc(b, compare)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"desa-agent/internal/models"
+)
+
+// ErrHistoryDisabled is returned when user history is read without an
+// encryption key configured.
+var ErrHistoryDisabled = errors.New("user history is disabled")
+
+// upsertUsers stores users, recording a version for each one that changed.
+// Every write path of users goes through it or removeUser, so that the
+// history is complete. Versions are committed together with the users they
+// describe.
+func (uc *UsersUseCase) upsertUsers(ctx context.Context, users []models.User) error {
+	var versions []models.UserVersion
+	if uc.history.Cipher != nil {
+		now := time.Now().UTC()
+		for i := range users {
+			old, err := uc.storage.GetUser(ctx, users[i].UserHash)
+			if err != nil {
+				return fmt.Errorf("storage.GetUser: %w", err)
+			}
+
+			version, err := uc.newVersion(old, &users[i], now)
+			if err != nil {
+				return err
+			}
+			if version != nil {
+				versions = append(versions, *version)
+			}
+		}
+	}
+
+	if err := uc.storage.UpsertUsers(ctx, users, versions); err != nil {
+		return fmt.Errorf("storage.UpsertUsers: %w", err)
+	}
+
+	return nil
+}
+
+// removeUser deletes a stored user, recording its removal.
+func (uc *UsersUseCase) removeUser(ctx context.Context, userHash string) error {
+	var versions []models.UserVersion
+	if uc.history.Cipher != nil {
+		old, err := uc.storage.GetUser(ctx, userHash)
+		if err != nil {
+			return fmt.Errorf("storage.GetUser: %w", err)
+		}
+
+		version, err := uc.newVersion(old, nil, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if version != nil {
+			versions = append(versions, *version)
+		}
+	}
+
+	if err := uc.storage.RemoveUser(ctx, userHash, versions); err != nil {
+		return fmt.Errorf("storage.RemoveUser: %w", err)
+	}
+
+	return nil
+}
+
+// newVersion describes the change from old to new, where nil stands for a
+// user that is not stored. It returns nil when nothing changed.
+func (uc *UsersUseCase) newVersion(old, new *models.User, at time.Time) (*models.UserVersion, error) {
+	version := &models.UserVersion{
+		ID:        versionID(at),
+		ChangedAt: at,
+	}
+
+	var before, after models.User
+	switch {
+	case old == nil && new == nil:
+		return nil, nil
+	case old == nil:
+		version.Action = models.ChangeActionCreate
+		after = *new
+	case new == nil:
+		version.Action = models.ChangeActionDelete
+		before = *old
+	default:
+		version.Action = models.ChangeActionUpdate
+		before, after = *old, *new
+	}
+	if new != nil {
+		version.UserHash, version.Source = new.UserHash, new.Source
+	} else {
+		version.UserHash, version.Source = old.UserHash, old.Source
+	}
+
+	version.Fields = changedFields(before, after)
+	if len(version.Fields) == 0 {
+		return nil, nil
+	}
+
+	oldValues, err := fieldValues(before)
+	if err != nil {
+		return nil, err
+	}
+	newValues, err := fieldValues(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.FieldChange, 0, len(version.Fields))
+	for _, field := range version.Fields {
+		changes = append(changes, models.FieldChange{
+			Field: field,
+			Old:   oldValues[field],
+			New:   newValues[field],
+		})
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("marshal field changes: %w", err)
+	}
+	version.Sealed = uc.history.Cipher.Seal(data, versionAAD(version))
+
+	return version, nil
+}
+
+// versionID orders versions by time when compared as strings.
+func versionID(at time.Time) string {
+	return fmt.Sprintf("%020d", at.UnixNano())
+}
+
+// versionAAD binds sealed values to the version they belong to.
+func versionAAD(version *models.UserVersion) []byte {
+	return []byte(version.UserHash + "\x00" + version.ID)
+}
+
+// fieldValues returns the JSON encoded values of the fields of user, named
+// like changedFields does. Empty fields marked omitempty are left out.
+func fieldValues(user models.User) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return nil, fmt.Errorf("marshal user: %w", err)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("unmarshal user fields: %w", err)
+	}
+
+	if pii, ok := values["pii"]; ok {
+		delete(values, "pii")
+
+		var piiValues map[string]json.RawMessage
+		if err := json.Unmarshal(pii, &piiValues); err != nil {
+			return nil, fmt.Errorf("unmarshal user pii fields: %w", err)
+		}
+		for name, value := range piiValues {
+			values["pii."+name] = value
+		}
+	}
+
+	return values, nil
+}
+
+// GetUserHistory returns up to limit versions of a user older than the
+// version with ID before, newest first. Only versions changing one of fields
+// are returned unless it is empty. Values are decrypted when includePII is
+// set.
+func (uc *UsersUseCase) GetUserHistory(ctx context.Context, userHash string, includePII bool, fields []string, before string, limit int) ([]models.UserVersion, error) {
+	if uc.history.Cipher == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	versions, err := uc.storage.ListUserVersions(ctx, userHash, before, fields, limit)
+	if err != nil {
+		return nil, fmt.Errorf("storage.ListUserVersions: %w", err)
+	}
+
+	if !includePII {
+		return versions, nil
+	}
+
+	for i := range versions {
+		if err := uc.openVersion(&versions[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return versions, nil
+}
+
+// openVersion decrypts the values of version into its Changes.
+func (uc *UsersUseCase) openVersion(version *models.UserVersion) error {
+	data, err := uc.history.Cipher.Open(version.Sealed, versionAAD(version))
+	if err != nil {
+		return fmt.Errorf("version %s of user %s: %w", version.ID, version.UserHash, err)
+	}
+
+	if err := json.Unmarshal(data, &version.Changes); err != nil {
+		return fmt.Errorf("unmarshal field changes of version %s: %w", version.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"desa-agent/internal/models"
+)
+
+// CorrelateUsers links stored accounts that share a value of any configured
+// correlation key into persons and replaces the stored person set. Accounts
+// without a match form a person of their own.
+func (uc *UsersUseCase) CorrelateUsers(ctx context.Context) error {
+	uc.correlateMu.Lock()
+	defer uc.correlateMu.Unlock()
+
+	usersCh, errCh := uc.storage.ListUsers(ctx)
+	var users []models.User
+	for user := range usersCh {
+		users = append(users, user)
+	}
+	if err := <-errCh; err != nil {
+		return fmt.Errorf("storage.ListUsers: %w", err)
+	}
+
+	persons := correlate(users, uc.correlationKeys)
+
+	if err := uc.storage.ReplacePersons(ctx, persons); err != nil {
+		return fmt.Errorf("storage.ReplacePersons: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *UsersUseCase) GetPerson(ctx context.Context, personHash string, includePII bool) (*models.Person, error) {
+	person, err := uc.storage.GetPerson(ctx, personHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	if person == nil {
+		return nil, nil
+	}
+
+	if err := uc.loadAccounts(ctx, person, includePII); err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
+
+// GetPersonByUser returns the person the given account is linked to.
+func (uc *UsersUseCase) GetPersonByUser(ctx context.Context, userHash string, includePII bool) (*models.Person, error) {
+	personHash, err := uc.storage.GetPersonHash(ctx, userHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person hash: %w", err)
+	}
+
+	if personHash == "" {
+		return nil, nil
+	}
+
+	return uc.GetPerson(ctx, personHash, includePII)
+}
+
+func (uc *UsersUseCase) ListPersons(ctx context.Context, includePII bool) (<-chan models.Person, <-chan error) {
+	personsCh, storageErrCh := uc.storage.ListPersons(ctx)
+
+	outCh := make(chan models.Person)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(outCh)
+		defer close(errCh)
+
+		for person := range personsCh {
+			if err := uc.loadAccounts(ctx, &person, includePII); err != nil {
+				errCh <- err
+				return
+			}
+
+			select {
+			case outCh <- person:
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			}
+		}
+
+		if err := <-storageErrCh; err != nil {
+			errCh <- fmt.Errorf("storage error: %w", err)
+		}
+	}()
+
+	return outCh, errCh
+}
+
+func (uc *UsersUseCase) loadAccounts(ctx context.Context, person *models.Person, includePII bool) error {
+	person.Accounts = make([]models.User, 0, len(person.UserHashes))
+	for _, userHash := range person.UserHashes {
+		user, err := uc.GetUser(ctx, userHash, includePII)
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			person.Accounts = append(person.Accounts, *user)
+		}
+	}
+	return nil
+}
+
+// correlate groups users connected by equal normalized key values. The person
+// hash is derived from the smallest value of the first key present in the
+// group, so it stays stable as accounts join or leave.
+func correlate(users []models.User, keys []models.CorrelationKey) []models.Person {
+	parent := make([]int, len(users))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owners := make(map[string]int)
+	for i, user := range users {
+		for _, key := range keys {
+			value := correlationValue(user, key)
+			if value == "" {
+				continue
+			}
+
+			k := string(key) + "\x00" + value
+			if j, ok := owners[k]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				owners[k] = i
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range users {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+
+	persons := make([]models.Person, 0, len(groups))
+	for _, members := range groups {
+		person := models.Person{
+			PersonHash: personHash(users, members, keys),
+			UserHashes: make([]string, 0, len(members)),
+		}
+		for _, i := range members {
+			person.UserHashes = append(person.UserHashes, users[i].UserHash)
+		}
+		sort.Strings(person.UserHashes)
+
+		persons = append(persons, person)
+	}
+
+	return persons
+}
+
+func personHash(users []models.User, members []int, keys []models.CorrelationKey) string {
+	for _, key := range keys {
+		var anchor string
+		for _, i := range members {
+			value := correlationValue(users[i], key)
+			if value != "" && (anchor == "" || value < anchor) {
+				anchor = value
+			}
+		}
+
+		if anchor != "" {
+			return hashPerson(string(key), anchor)
+		}
+	}
+
+	anchor := users[members[0]].UserHash
+	for _, i := range members[1:] {
+		anchor = min(anchor, users[i].UserHash)
+	}
+	return hashPerson("user_hash", anchor)
+}
+
+func hashPerson(key, value string) string {
+	hash := sha256.Sum256([]byte("person\x00" + key + "\x00" + value))
+	return hex.EncodeToString(hash[:])
+}
+
+func correlationValue(user models.User, key models.CorrelationKey) string {
+	if user.PII == nil {
+		return ""
+	}
+
+	switch key {
+	case models.CorrelationKeyEmployeeID:
+		return strings.ToLower(strings.TrimSpace(user.PII.EmployeeID))
+	case models.CorrelationKeyEmail:
+		return normalizeEmail(user.PII.Email)
+	default:
+		return ""
+	}
+}
+
+// normalizeEmail lowercases the address and drops any "+tag" from the local
+// part, so alias spellings of one mailbox correlate.
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" || domain == "" {
+		return ""
+	}
+
+	if tagged, _, found := strings.Cut(local, "+"); found {
+		local = tagged
+	}
+
+	return local + "@" + domain
+}
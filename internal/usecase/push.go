@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"desa-agent/internal/models"
+)
+
+// ErrUserNameTaken is returned by PushUser when another user of the source
+// already has the username.
+var ErrUserNameTaken = errors.New("username is already in use")
+
+// PushUser stores a user pushed by an identity provider, e.g. over SCIM.
+// It is hashed and written exactly like a user read by SyncUsers. Usernames
+// are unique within the source, compared case-insensitively.
+func (uc *UsersUseCase) PushUser(ctx context.Context, source string, user models.User) (*models.User, error) {
+	if user.PII == nil || user.PII.SourceID == "" {
+		return nil, fmt.Errorf("source id is required")
+	}
+
+	users := []models.User{user}
+	uc.prepareUsers(source, users)
+
+	// Concurrent pushes of the same username must not both pass the check.
+	unlock, err := uc.lockSource(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := uc.checkUserName(ctx, source, users[0]); err != nil {
+		return nil, err
+	}
+
+	if err := uc.upsertUsers(ctx, users); err != nil {
+		return nil, err
+	}
+
+	uc.requestCorrelation()
+	return &users[0], nil
+}
+
+// GetSourceUser returns the user with the given source ID, or nil if the
+// source has no such user.
+func (uc *UsersUseCase) GetSourceUser(ctx context.Context, source, sourceID string) (*models.User, error) {
+	user, err := uc.storage.GetUser(ctx, HashUserID(source, sourceID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil || user.Source != source {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+func (uc *UsersUseCase) ListSourceUsers(ctx context.Context, source string) ([]models.User, error) {
+	usersCh, errCh := uc.storage.ListUsers(ctx)
+
+	var users []models.User
+	for user := range usersCh {
+		if user.Source == source {
+			users = append(users, user)
+		}
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, fmt.Errorf("storage.ListUsers: %w", err)
+	}
+
+	return users, nil
+}
+
+// checkUserName returns ErrUserNameTaken when another user of source has the
+// username of user.
+func (uc *UsersUseCase) checkUserName(ctx context.Context, source string, user models.User) error {
+	if user.PII.Username == "" {
+		return nil
+	}
+
+	users, err := uc.ListSourceUsers(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range users {
+		if other.UserHash != user.UserHash && other.PII != nil && strings.EqualFold(other.PII.Username, user.PII.Username) {
+			return fmt.Errorf("%w: %s", ErrUserNameTaken, user.PII.Username)
+		}
+	}
+	return nil
+}
+
+// RemoveSourceUser deletes a pushed user. It reports false if the user did
+// not exist.
+func (uc *UsersUseCase) RemoveSourceUser(ctx context.Context, source, sourceID string) (bool, error) {
+	user, err := uc.GetSourceUser(ctx, source, sourceID)
+	if err != nil || user == nil {
+		return false, err
+	}
+
+	if err := uc.removeUser(ctx, user.UserHash); err != nil {
+		return false, err
+	}
+
+	uc.requestCorrelation()
+	return true, nil
+}
+
+// PushGroup stores a group pushed by an identity provider. Member IDs are
+// resolved to user hashes of the same source.
+func (uc *UsersUseCase) PushGroup(ctx context.Context, source string, group models.Group) (*models.Group, error) {
+	if group.PII == nil || group.PII.SourceID == "" {
+		return nil, fmt.Errorf("source id is required")
+	}
+
+	group.Source = source
+	group.GroupHash = HashGroupID(source, group.PII.SourceID)
+	group.MemberHashes = make([]string, 0, len(group.PII.MemberIDs))
+	for _, memberID := range group.PII.MemberIDs {
+		group.MemberHashes = append(group.MemberHashes, HashUserID(source, memberID))
+	}
+
+	if err := uc.storage.UpsertGroups(ctx, []models.Group{group}); err != nil {
+		return nil, fmt.Errorf("storage.UpsertGroups: %w", err)
+	}
+
+	return &group, nil
+}
+
+func (uc *UsersUseCase) GetSourceGroup(ctx context.Context, source, sourceID string) (*models.Group, error) {
+	group, err := uc.storage.GetGroup(ctx, HashGroupID(source, sourceID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	if group == nil || group.Source != source {
+		return nil, nil
+	}
+
+	return group, nil
+}
+
+func (uc *UsersUseCase) ListSourceGroups(ctx context.Context, source string) ([]models.Group, error) {
+	groupsCh, errCh := uc.storage.ListGroups(ctx)
+
+	var groups []models.Group
+	for group := range groupsCh {
+		if group.Source == source {
+			groups = append(groups, group)
+		}
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, fmt.Errorf("storage.ListGroups: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (uc *UsersUseCase) RemoveSourceGroup(ctx context.Context, source, sourceID string) (bool, error) {
+	group, err := uc.GetSourceGroup(ctx, source, sourceID)
+	if err != nil || group == nil {
+		return false, err
+	}
+
+	if err := uc.storage.RemoveGroup(ctx, group.GroupHash); err != nil {
+		return false, fmt.Errorf("storage.RemoveGroup: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"desa-agent/internal/models"
+)
+
+// ErrNotRefreshable is returned by RefreshUser for users whose source is not
+// a configured identity provider, such as users pushed over SCIM.
+var ErrNotRefreshable = errors.New("user source cannot be refreshed")
+
+// RefreshUser reads one stored user again from its identity provider and
+// stores the result, without syncing the rest of the source. It returns the
+// fresh user, or nil when the user is not stored or the provider no longer
+// has it; in the latter case the stored record is removed.
+func (uc *UsersUseCase) RefreshUser(ctx context.Context, userHash string, includePII bool) (*models.User, error) {
+	ref, err := uc.userRef(ctx, userHash)
+	if err != nil || ref == nil {
+		return nil, err
+	}
+
+	source, ok := uc.sources[ref.Source]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRefreshable, ref.Source)
+	}
+
+	// A sync of the source running meanwhile could overwrite the fresh
+	// record with what it read before.
+	unlock, err := uc.lockSource(ctx, source.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	idpUser, err := source.IDP.GetUser(ctx, ref.SourceID)
+	if err != nil {
+		return nil, fmt.Errorf("idp.GetUser: %w", err)
+	}
+
+	if idpUser == nil {
+		if err := uc.removeUser(ctx, userHash); err != nil {
+			return nil, err
+		}
+		uc.requestCorrelation()
+		return nil, nil
+	}
+
+	users := []models.User{*idpUser}
+	uc.prepareUsers(source.Name, users)
+	user := users[0]
+	if user.UserHash != userHash {
+		return nil, fmt.Errorf("identity provider returned user %s for source id %s", user.UserHash, ref.SourceID)
+	}
+
+	if err := uc.upsertUsers(ctx, users); err != nil {
+		return nil, err
+	}
+	uc.requestCorrelation()
+
+	if !includePII {
+		user.PII = nil
+	}
+	return &user, nil
+}
+
+// userRef returns where a stored user comes from, or nil if it is not stored.
+// Records written before references were kept carry their source ID in PII.
+func (uc *UsersUseCase) userRef(ctx context.Context, userHash string) (*models.UserRef, error) {
+	ref, err := uc.storage.GetUserRef(ctx, userHash)
+	if err != nil {
+		return nil, fmt.Errorf("storage.GetUserRef: %w", err)
+	}
+	if ref != nil {
+		return ref, nil
+	}
+
+	user, err := uc.storage.GetUser(ctx, userHash)
+	if err != nil {
+		return nil, fmt.Errorf("storage.GetUser: %w", err)
+	}
+	if user == nil || user.PII == nil || user.PII.SourceID == "" {
+		return nil, nil
+	}
+	return &models.UserRef{Source: user.Source, SourceID: user.PII.SourceID}, nil
+}
+
+// lockSource waits until no other sync, refresh or push of source runs and
+// returns the function releasing it. Locks of pushed sources, which are not
+// configured, are created on first use.
+func (uc *UsersUseCase) lockSource(ctx context.Context, source string) (func(), error) {
+	uc.sourceLocksMu.Lock()
+	lock, ok := uc.sourceLocks[source]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		uc.sourceLocks[source] = lock
+	}
+	uc.sourceLocksMu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
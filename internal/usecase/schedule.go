@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"desa-agent/internal/models"
+)
+
+// Schedule returns the next activation time after t. Parsed cron
+// expressions satisfy it.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// Every is a Schedule activating at a fixed interval.
+type Every time.Duration
+
+func (e Every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// SyncSchedule decides when a source is synced. Incremental and full syncs
+// follow separate schedules; every run is delayed by up to Jitter and moved
+// out of the quiet windows. Failed runs are retried after RetryBackoff,
+// doubled per consecutive failure up to RetryMaxBackoff.
+type SyncSchedule struct {
+	Incremental  Schedule
+	Full         Schedule
+	Jitter       time.Duration
+	QuietWindows []models.QuietWindow
+
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+}
+
+// next returns the next run of schedule after now.
+func (s SyncSchedule) next(schedule Schedule, now time.Time) time.Time {
+	t := schedule.Next(now)
+
+	// Adjacent windows can push a run into one another, so the check is
+	// repeated; jitter is drawn again so that runs postponed to the end of
+	// a window do not all start at once.
+	for range len(s.QuietWindows) + 1 {
+		t = t.Add(s.jitter())
+
+		end, quiet := s.quietUntil(t)
+		if !quiet {
+			return t
+		}
+		t = end
+	}
+	return t
+}
+
+// retry returns when to retry after the given number of consecutive
+// failures. Half of the backoff is randomized so that agents failing
+// together do not retry together.
+func (s SyncSchedule) retry(failures int, now time.Time) time.Time {
+	backoff := s.RetryBackoff
+	for i := 1; i < failures && backoff < s.RetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, s.RetryMaxBackoff)
+	if backoff > 1 {
+		backoff = backoff/2 + rand.N(backoff/2)
+	}
+
+	t := now.Add(backoff)
+	if end, quiet := s.quietUntil(t); quiet {
+		return end.Add(s.jitter())
+	}
+	return t
+}
+
+func (s SyncSchedule) jitter() time.Duration {
+	if s.Jitter <= 0 {
+		return 0
+	}
+	return rand.N(s.Jitter)
+}
+
+func (s SyncSchedule) quietUntil(t time.Time) (time.Time, bool) {
+	year, month, day := t.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	for _, w := range s.QuietWindows {
+		if !w.Contains(offset) {
+			continue
+		}
+		// A window spanning midnight that was entered before midnight ends
+		// the next day.
+		if offset >= w.End {
+			return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location()).Add(w.End), true
+		}
+		return midnight.Add(w.End), true
+	}
+	return time.Time{}, false
+}
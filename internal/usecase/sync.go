@@ -2,82 +2,523 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"desa-agent/internal/models"
 )
 
-const syncInterval = 1 * time.Minute
-
 func (u *UsersUseCase) StartSyncJob(ctx context.Context, logger *slog.Logger) {
-	ticker := time.NewTicker(syncInterval)
-	defer ticker.Stop()
+	if err := u.restoreSyncStatuses(ctx); err != nil {
+		logger.Error("failed to restore sync statuses", "error", err)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		u.runCorrelationJob(ctx, logger)
+	}()
+
+	for _, source := range u.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u.runSourceSyncJob(ctx, source, logger.With("source", source.Name))
+		}()
+	}
+
+	wg.Wait()
+	logger.Info("sync job stopped")
+}
+
+// runSourceSyncJob syncs one source on its schedule, starting with a full
+// sync right away. Runs of the full schedule, and every run until a full sync
+// succeeds, reconcile the whole source; the others are incremental where the
+// provider supports it. Transient failures are retried with backoff, while a
+// permanent failure stops the scheduled runs.
+// Providers implementing ChangeNotifier are also synced as soon as they
+// report a change, regardless of quiet windows.
+func (u *UsersUseCase) runSourceSyncJob(ctx context.Context, source Source, logger *slog.Logger) {
+	schedule := source.Schedule
+
+	var changes <-chan struct{}
+	if notifier, ok := source.IDP.(ChangeNotifier); ok {
+		changes = notifier.Changes()
+	}
+
+	now := time.Now()
+	nextIncremental := now
+	nextFull := schedule.next(schedule.Full, now)
+	fullDue := true
+	halted := false
+
+	// doSync runs one sync, updating the job state from its outcome, and
+	// reports whether it failed with a transient error. Until a full sync
+	// succeeds every run is full.
+	doSync := func(run models.SyncRun) bool {
+		if fullDue {
+			run.Mode = models.SyncModeFull
+		}
+
+		err := u.runSync(ctx, run, logger)
+		switch {
+		case ctx.Err() != nil:
+			return false
+		case err == nil:
+			halted = false
+			if run.Mode == models.SyncModeFull {
+				fullDue = false
+				u.markSynced(source.Name)
+			}
+			return false
+		case errors.Is(err, models.ErrPermanent):
+			halted = true
+			logger.Error("scheduled user syncs stopped after a permanent failure")
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
+		var tick <-chan time.Time
+		if !halted {
+			next := nextIncremental
+			if nextFull.Before(next) {
+				next = nextFull
+			}
+			logger.Debug("next user sync scheduled", "at", next)
+			timer.Reset(time.Until(next))
+			tick = timer.C
+		}
+
 		select {
 		case <-ctx.Done():
-			logger.Info("sync job stopped")
 			return
-		case <-ticker.C:
-			u.runSync(ctx, logger)
+		case <-tick:
+			if !time.Now().Before(nextFull) {
+				fullDue = true
+			}
+			retry := doSync(u.newRun(source.Name, models.SyncModeIncremental))
+
+			now := time.Now()
+			if !now.Before(nextFull) {
+				nextFull = schedule.next(schedule.Full, now)
+			}
+			nextIncremental = schedule.next(schedule.Incremental, now)
+			if retry {
+				nextIncremental = u.scheduleRetry(source, now, nextIncremental)
+			}
+		case <-changes:
+			logger.Info("identity provider reported changes")
+			if doSync(u.newRun(source.Name, models.SyncModeFull)) {
+				nextIncremental = u.scheduleRetry(source, time.Now(), nextIncremental)
+			}
+		case <-u.triggers[source.Name]:
+			run := u.dequeueRun(source.Name)
+			if run == nil {
+				continue
+			}
+			logger.Info("sync triggered", "run_id", run.ID)
+			if doSync(*run) {
+				nextIncremental = u.scheduleRetry(source, time.Now(), nextIncremental)
+			}
 		}
 	}
 }
 
-func (u *UsersUseCase) runSync(ctx context.Context, logger *slog.Logger) {
-	logger.Info("starting user sync")
-	if err := u.SyncUsers(ctx); err != nil {
-		logger.Error("user sync failed", "error", err)
+// newRun returns a run of source that has not started yet.
+func (u *UsersUseCase) newRun(source string, mode models.SyncMode) models.SyncRun {
+	return models.SyncRun{
+		ID:     newRunID(),
+		Source: source,
+		Mode:   mode,
+		State:  models.SyncRunStateQueued,
+	}
+}
+
+// runSync runs one sync and records it as a sync run and in the source's
+// status.
+func (u *UsersUseCase) runSync(ctx context.Context, run models.SyncRun, logger *slog.Logger) error {
+	source, mode := run.Source, run.Mode
+	run.State = models.SyncRunStateRunning
+	run.StartedAt = time.Now().UTC()
+
+	// Run records are written even when the sync is cut short by shutdown.
+	storeCtx := context.WithoutCancel(ctx)
+	if err := u.storage.PutSyncRun(storeCtx, run); err != nil {
+		logger.Error("failed to record sync run", "run_id", run.ID, "error", err)
+	}
+
+	logger.Info("starting user sync", "mode", mode, "run_id", run.ID)
+	var stats models.SyncStats
+	unlock, err := u.lockSource(ctx, source)
+	if err == nil {
+		stats, err = u.SyncUsers(ctx, source, mode)
+		unlock()
+	}
+
+	run.FinishedAt = time.Now().UTC()
+	run.Stats = stats
+	run.State = models.SyncRunStateSucceeded
+	if err != nil {
+		run.State = models.SyncRunStateFailed
+		run.Error = err.Error()
+	}
+	if err := u.storage.PutSyncRun(storeCtx, run); err != nil {
+		logger.Error("failed to record sync run", "run_id", run.ID, "error", err)
+	}
+
+	if err != nil && ctx.Err() != nil {
+		// Interrupted by shutdown, not a failure of the source.
+		return err
+	}
+
+	status := u.recordSync(run, err)
+	if err := u.storage.PutSyncStatus(storeCtx, status); err != nil {
+		logger.Error("failed to record sync status", "error", err)
+	}
+
+	if err != nil {
+		logger.Error("user sync failed",
+			"mode", mode,
+			"run_id", run.ID,
+			"error", err,
+			"permanent", status.Alert,
+			"consecutive_failures", status.ConsecutiveFailures,
+		)
+		return err
+	}
+	logger.Info("user sync completed successfully",
+		"mode", mode,
+		"run_id", run.ID,
+		"created", stats.Created,
+		"updated", stats.Updated,
+		"deleted", stats.Deleted,
+		"unchanged", stats.Unchanged,
+	)
+
+	u.requestCorrelation()
+	return nil
+}
+
+// newRunID returns a UUIDv7, which sorts in creation order.
+func newRunID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// recordSync updates the status of the run's source after it ended with err.
+func (u *UsersUseCase) recordSync(run models.SyncRun, err error) models.SyncStatus {
+	u.statusMu.Lock()
+	defer u.statusMu.Unlock()
+
+	status := u.statuses[run.Source]
+	status.LastRunID = run.ID
+	status.NextRetryAt = time.Time{}
+	if err == nil {
+		status.ConsecutiveFailures = 0
+		status.LastError = ""
+		status.LastSuccessAt = run.FinishedAt
+		status.Alert = false
 	} else {
-		logger.Info("user sync completed successfully")
+		status.ConsecutiveFailures++
+		status.LastError = err.Error()
+		status.LastFailureAt = run.FinishedAt
+		status.Alert = errors.Is(err, models.ErrPermanent)
+	}
+	return *status
+}
+
+// scheduleRetry returns when to run the failed sync of source again: after
+// its backoff, or at the next scheduled run when that comes first.
+func (u *UsersUseCase) scheduleRetry(source Source, now, next time.Time) time.Time {
+	u.statusMu.Lock()
+	defer u.statusMu.Unlock()
+
+	status := u.statuses[source.Name]
+	if retry := source.Schedule.retry(status.ConsecutiveFailures, now); retry.Before(next) {
+		next = retry
+	}
+	status.NextRetryAt = next
+	return next
+}
+
+// restoreSyncStatuses loads the statuses persisted before a restart.
+func (u *UsersUseCase) restoreSyncStatuses(ctx context.Context) error {
+	statusCh, errCh := u.storage.ListSyncStatuses(ctx)
+
+	u.statusMu.Lock()
+	defer u.statusMu.Unlock()
+
+	for stored := range statusCh {
+		if _, ok := u.statuses[stored.Source]; ok {
+			u.statuses[stored.Source] = &stored
+		}
 	}
+
+	return <-errCh
+}
+
+// SyncStatuses returns the sync status of every source, ordered by name.
+func (u *UsersUseCase) SyncStatuses() []models.SyncStatus {
+	u.statusMu.Lock()
+	defer u.statusMu.Unlock()
+
+	statuses := make([]models.SyncStatus, 0, len(u.statuses))
+	for _, status := range u.statuses {
+		statuses = append(statuses, *status)
+	}
+	slices.SortFunc(statuses, func(a, b models.SyncStatus) int {
+		return strings.Compare(a.Source, b.Source)
+	})
+	return statuses
 }
 
-func (u *UsersUseCase) SyncUsers(ctx context.Context) error {
-	idpUsers, err := u.idp.ListUsers(ctx)
+func (u *UsersUseCase) GetSyncRun(ctx context.Context, id string) (*models.SyncRun, error) {
+	run, err := u.storage.GetSyncRun(ctx, id)
 	if err != nil {
-		return fmt.Errorf("idp.ListUsers: %w", err)
+		return nil, fmt.Errorf("storage.GetSyncRun: %w", err)
 	}
+	return run, nil
+}
 
-	dbUsers, err := u.collectDBUsers(ctx)
+// ListSyncRuns returns up to limit runs of source, or of every source when
+// it is empty, that started before the run with ID before. Runs are ordered
+// newest first.
+func (u *UsersUseCase) ListSyncRuns(ctx context.Context, source, before string, limit int) ([]models.SyncRun, error) {
+	runs, err := u.storage.ListSyncRuns(ctx, source, before, limit)
 	if err != nil {
-		return fmt.Errorf("collectDBUsers: %w", err)
+		return nil, fmt.Errorf("storage.ListSyncRuns: %w", err)
+	}
+	return runs, nil
+}
+
+// runCorrelationJob re-links persons whenever stored users change. Requests
+// arriving while a correlation runs are coalesced into a single follow-up.
+func (u *UsersUseCase) runCorrelationJob(ctx context.Context, logger *slog.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-u.correlateCh:
+			if err := u.CorrelateUsers(ctx); err != nil {
+				logger.Error("user correlation failed", "error", err)
+			}
+		}
+	}
+}
+
+func (u *UsersUseCase) requestCorrelation() {
+	select {
+	case u.correlateCh <- struct{}{}:
+	default:
+	}
+}
+
+// SyncUsers reconciles the stored users of one source with its identity
+// provider. Users of other sources are never touched. An incremental sync
+// falls back to a full one for providers without delta support.
+func (u *UsersUseCase) SyncUsers(ctx context.Context, sourceName string, mode models.SyncMode) (models.SyncStats, error) {
+	var stats models.SyncStats
+
+	source, ok := u.sources[sourceName]
+	if !ok {
+		return stats, fmt.Errorf("unknown source: %s", sourceName)
+	}
+
+	if delta, ok := source.IDP.(DeltaProvider); ok && mode == models.SyncModeIncremental {
+		return u.syncUserChanges(ctx, source, delta)
+	}
+
+	plan, err := u.planSync(ctx, source, false)
+	if err != nil {
+		return stats, err
+	}
+
+	if len(plan.upserts) > 0 {
+		if err := u.upsertUsers(ctx, plan.upserts); err != nil {
+			return stats, err
+		}
+	}
+
+	stats = plan.stats
+	stats.Deleted = 0
+	for _, userHash := range plan.removals {
+		if err := u.removeUser(ctx, userHash); err != nil {
+			return stats, err
+		}
+		stats.Deleted++
+	}
+
+	return stats, nil
+}
+
+// syncPlan holds the writes that reconcile the stored users of a source with
+// its identity provider.
+type syncPlan struct {
+	stats    models.SyncStats
+	upserts  []models.User
+	removals []string
+	// changes describes every write; it is only filled for previews.
+	changes []models.UserChange
+}
+
+// planSync compares the users of source with the stored ones without
+// writing anything.
+func (u *UsersUseCase) planSync(ctx context.Context, source Source, describe bool) (syncPlan, error) {
+	var plan syncPlan
+
+	idpUsers, err := source.IDP.ListUsers(ctx)
+	if err != nil {
+		return plan, fmt.Errorf("idp.ListUsers: %w", err)
+	}
+
+	u.prepareUsers(source.Name, idpUsers)
+
+	stored, err := u.collectFingerprints(ctx, source.Name)
+	if err != nil {
+		return plan, fmt.Errorf("collectFingerprints: %w", err)
 	}
 
-	usersToUpsert := make([]models.User, 0)
 	for _, idpUser := range idpUsers {
-		dbUser, exists := dbUsers[idpUser.UserHash]
-		if !exists || !reflect.DeepEqual(idpUser, dbUser) {
-			usersToUpsert = append(usersToUpsert, idpUser)
+		fingerprint, exists := stored[idpUser.UserHash]
+		switch {
+		case !exists:
+			plan.stats.Created++
+			plan.upserts = append(plan.upserts, idpUser)
+			if describe {
+				plan.changes = append(plan.changes, models.UserChange{
+					UserHash: idpUser.UserHash,
+					Action:   models.ChangeActionCreate,
+				})
+			}
+		case fingerprint != idpUser.Fingerprint():
+			plan.stats.Updated++
+			plan.upserts = append(plan.upserts, idpUser)
+			if describe {
+				change, err := u.describeUpdate(ctx, idpUser)
+				if err != nil {
+					return plan, err
+				}
+				plan.changes = append(plan.changes, change)
+			}
+		default:
+			plan.stats.Unchanged++
 		}
+		delete(stored, idpUser.UserHash)
 	}
 
-	if len(usersToUpsert) == 0 {
-		return nil
+	// Whatever is left in stored belongs to this source but is gone from it.
+	for userHash := range stored {
+		plan.stats.Deleted++
+		plan.removals = append(plan.removals, userHash)
+		if describe {
+			plan.changes = append(plan.changes, models.UserChange{
+				UserHash: userHash,
+				Action:   models.ChangeActionDelete,
+			})
+		}
 	}
 
-	err = u.storage.UpsertUsers(ctx, usersToUpsert)
+	return plan, nil
+}
+
+func (u *UsersUseCase) syncUserChanges(ctx context.Context, source Source, delta DeltaProvider) (models.SyncStats, error) {
+	var stats models.SyncStats
+
+	changes, err := delta.ListUserChanges(ctx)
 	if err != nil {
-		return fmt.Errorf("storage.UpsertUsers: %w", err)
+		return stats, fmt.Errorf("idp.ListUserChanges: %w", err)
 	}
 
-	return nil
+	u.prepareUsers(source.Name, changes.Changed)
+
+	usersToUpsert := make([]models.User, 0)
+	for _, idpUser := range changes.Changed {
+		stored, err := u.storage.GetUserFingerprint(ctx, idpUser.UserHash)
+		if err != nil {
+			return stats, fmt.Errorf("storage.GetUserFingerprint: %w", err)
+		}
+		switch {
+		case stored == nil:
+			stats.Created++
+			usersToUpsert = append(usersToUpsert, idpUser)
+		case stored.Fingerprint != idpUser.Fingerprint():
+			stats.Updated++
+			usersToUpsert = append(usersToUpsert, idpUser)
+		default:
+			stats.Unchanged++
+		}
+	}
+
+	if len(usersToUpsert) > 0 {
+		if err := u.upsertUsers(ctx, usersToUpsert); err != nil {
+			return models.SyncStats{}, err
+		}
+	}
+
+	for _, sourceID := range changes.RemovedIDs {
+		userHash := HashUserID(source.Name, sourceID)
+		stored, err := u.storage.GetUserFingerprint(ctx, userHash)
+		if err != nil {
+			return stats, fmt.Errorf("storage.GetUserFingerprint: %w", err)
+		}
+		if stored == nil {
+			continue
+		}
+
+		if err := u.removeUser(ctx, userHash); err != nil {
+			return stats, err
+		}
+		stats.Deleted++
+	}
+
+	return stats, nil
+}
+
+// prepareUsers tags users with their source, derives their hashes and
+// classifies them. Every write path goes through it so that hashes and
+// account types are computed in one place.
+func (u *UsersUseCase) prepareUsers(source string, users []models.User) {
+	for i := range users {
+		users[i].Source = source
+		users[i].AccountType = u.classifier.Classify(&users[i])
+		if users[i].PII != nil {
+			users[i].UserHash = HashUserID(source, users[i].PII.SourceID)
+		}
+	}
 }
 
-func (u *UsersUseCase) collectDBUsers(ctx context.Context) (map[string]models.User, error) {
-	usersCh, errCh := u.storage.ListUsers(ctx)
-	dbUsers := make(map[string]models.User)
+// collectFingerprints returns the fingerprints of the stored users of
+// source by user hash.
+func (u *UsersUseCase) collectFingerprints(ctx context.Context, source string) (map[string]string, error) {
+	fingerprintCh, errCh := u.storage.ListUserFingerprints(ctx)
+	fingerprints := make(map[string]string)
 
-	for user := range usersCh {
-		dbUsers[user.UserHash] = user
+	for fp := range fingerprintCh {
+		// Records written before sources existed carry no source and use the
+		// old hash scheme; the next sync of any source replaces them.
+		if fp.Source != source && fp.Source != "" {
+			continue
+		}
+		fingerprints[fp.UserHash] = fp.Fingerprint
 	}
 
 	if err := <-errCh; err != nil {
 		return nil, err
 	}
 
-	return dbUsers, nil
+	return fingerprints, nil
 }
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"desa-agent/internal/models"
+)
+
+// TriggerSync queues a sync of source outside its schedule and returns the
+// queued run, or nil when the source does not exist. The run is picked up by
+// the source's sync job, so it never overlaps another sync of the source.
+// While a triggered run waits, further triggers return it; a full request
+// upgrades a queued incremental run.
+func (u *UsersUseCase) TriggerSync(ctx context.Context, source string, mode models.SyncMode) (*models.SyncRun, error) {
+	if _, ok := u.sources[source]; !ok {
+		return nil, nil
+	}
+
+	u.queueMu.Lock()
+	defer u.queueMu.Unlock()
+
+	if queued := u.queued[source]; queued != nil {
+		if mode == models.SyncModeFull && queued.Mode != models.SyncModeFull {
+			queued.Mode = models.SyncModeFull
+			if err := u.storage.PutSyncRun(ctx, *queued); err != nil {
+				return nil, fmt.Errorf("storage.PutSyncRun: %w", err)
+			}
+		}
+		run := *queued
+		return &run, nil
+	}
+
+	run := u.newRun(source, mode)
+	if err := u.storage.PutSyncRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("storage.PutSyncRun: %w", err)
+	}
+	u.queued[source] = &run
+
+	select {
+	case u.triggers[source] <- struct{}{}:
+	default:
+	}
+
+	queued := run
+	return &queued, nil
+}
+
+// dequeueRun takes the triggered run of source off the queue.
+func (u *UsersUseCase) dequeueRun(source string) *models.SyncRun {
+	u.queueMu.Lock()
+	defer u.queueMu.Unlock()
+
+	run := u.queued[source]
+	delete(u.queued, source)
+	return run
+}
@@ -5,14 +5,40 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
+	"desa-agent/internal/encryption"
 	"desa-agent/internal/models"
 )
 
 type Storage interface {
 	GetUser(ctx context.Context, userHash string) (*models.User, error)
 	ListUsers(ctx context.Context) (<-chan models.User, <-chan error)
-	UpsertUsers(ctx context.Context, users []models.User) error
+	UpsertUsers(ctx context.Context, users []models.User, versions []models.UserVersion) error
+	RemoveUser(ctx context.Context, userHash string, versions []models.UserVersion) error
+	GetUserRef(ctx context.Context, userHash string) (*models.UserRef, error)
+	GetUserFingerprint(ctx context.Context, userHash string) (*models.UserFingerprint, error)
+	ListUserFingerprints(ctx context.Context) (<-chan models.UserFingerprint, <-chan error)
+	ListUserVersions(ctx context.Context, userHash, before string, fields []string, limit int) ([]models.UserVersion, error)
+	ListUserVersionsAfter(ctx context.Context, userHash, after string) ([]models.UserVersion, error)
+
+	GetPerson(ctx context.Context, personHash string) (*models.Person, error)
+	GetPersonHash(ctx context.Context, userHash string) (string, error)
+	ListPersons(ctx context.Context) (<-chan models.Person, <-chan error)
+	ReplacePersons(ctx context.Context, persons []models.Person) error
+
+	GetGroup(ctx context.Context, groupHash string) (*models.Group, error)
+	ListGroups(ctx context.Context) (<-chan models.Group, <-chan error)
+	UpsertGroups(ctx context.Context, groups []models.Group) error
+	RemoveGroup(ctx context.Context, groupHash string) error
+
+	PutSyncRun(ctx context.Context, run models.SyncRun) error
+	GetSyncRun(ctx context.Context, id string) (*models.SyncRun, error)
+	ListSyncRuns(ctx context.Context, source, before string, limit int) ([]models.SyncRun, error)
+	PutSyncStatus(ctx context.Context, status models.SyncStatus) error
+	ListSyncStatuses(ctx context.Context) (<-chan models.SyncStatus, <-chan error)
 }
 
 type IdentityProvider interface {
@@ -20,13 +46,137 @@ type IdentityProvider interface {
 	ListUsers(ctx context.Context) ([]models.User, error)
 }
 
+// DeltaProvider is implemented by identity providers that can report the
+// changes since their previous call, enabling incremental syncs.
+type DeltaProvider interface {
+	ListUserChanges(ctx context.Context) (*models.UserChanges, error)
+}
+
+// ChangeNotifier is implemented by identity providers that can tell when
+// their data changed, such as a watched export file. Each signal triggers a
+// full sync of the source outside its regular schedule.
+type ChangeNotifier interface {
+	Changes() <-chan struct{}
+}
+
+// UserHistory configures the per-user version history.
+type UserHistory struct {
+	// Cipher seals the values of user versions; nil turns history off.
+	Cipher *encryption.Cipher
+	// Since is when history was last enabled, and Retention how long
+	// versions are kept. Together they bound point-in-time queries.
+	Since     time.Time
+	Retention time.Duration
+}
+
+// Source is a named identity provider synced on its own schedule.
+type Source struct {
+	Name     string
+	IDP      IdentityProvider
+	Schedule SyncSchedule
+}
+
 type UsersUseCase struct {
-	storage Storage
-	idp     IdentityProvider
+	storage    Storage
+	sources    map[string]Source
+	classifier *Classifier
+	history    UserHistory
+
+	correlationKeys []models.CorrelationKey
+	correlateMu     sync.Mutex
+	correlateCh     chan struct{}
+
+	// unsynced holds the sources whose first full sync has not succeeded
+	// yet; ready is closed once it is empty.
+	readyMu  sync.Mutex
+	unsynced map[string]struct{}
+	ready    chan struct{}
+
+	statusMu sync.Mutex
+	statuses map[string]*models.SyncStatus
+
+	// queued holds the triggered run waiting for each source's sync job,
+	// which triggers wakes.
+	queueMu  sync.Mutex
+	queued   map[string]*models.SyncRun
+	triggers map[string]chan struct{}
+
+	// sourceLocks keep syncs, single-user refreshes and pushes of a source
+	// apart.
+	sourceLocksMu sync.Mutex
+	sourceLocks   map[string]chan struct{}
 }
 
-func NewUsersUseCase(storage Storage, idp IdentityProvider) *UsersUseCase {
-	return &UsersUseCase{storage: storage, idp: idp}
+func NewUsersUseCase(
+	storage Storage,
+	sources []Source,
+	correlationKeys []models.CorrelationKey,
+	classifier *Classifier,
+	history UserHistory,
+) *UsersUseCase {
+	byName := make(map[string]Source, len(sources))
+	unsynced := make(map[string]struct{}, len(sources))
+	statuses := make(map[string]*models.SyncStatus, len(sources))
+	triggers := make(map[string]chan struct{}, len(sources))
+	sourceLocks := make(map[string]chan struct{}, len(sources))
+	for _, source := range sources {
+		byName[source.Name] = source
+		unsynced[source.Name] = struct{}{}
+		statuses[source.Name] = &models.SyncStatus{Source: source.Name}
+		triggers[source.Name] = make(chan struct{}, 1)
+		sourceLocks[source.Name] = make(chan struct{}, 1)
+	}
+
+	uc := &UsersUseCase{
+		storage:         storage,
+		sources:         byName,
+		classifier:      classifier,
+		history:         history,
+		correlationKeys: correlationKeys,
+		correlateCh:     make(chan struct{}, 1),
+		unsynced:        unsynced,
+		ready:           make(chan struct{}),
+		statuses:        statuses,
+		queued:          make(map[string]*models.SyncRun),
+		triggers:        triggers,
+		sourceLocks:     sourceLocks,
+	}
+	if len(unsynced) == 0 {
+		close(uc.ready)
+	}
+
+	return uc
+}
+
+// Ready is closed once every source completed its first full sync.
+func (uc *UsersUseCase) Ready() <-chan struct{} {
+	return uc.ready
+}
+
+// HasUsers reports whether any user is stored, such as the ones kept from
+// before a restart.
+func (uc *UsersUseCase) HasUsers(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	usersCh, errCh := uc.storage.ListUsers(ctx)
+	for range usersCh {
+		return true, nil
+	}
+	return false, <-errCh
+}
+
+func (uc *UsersUseCase) markSynced(source string) {
+	uc.readyMu.Lock()
+	defer uc.readyMu.Unlock()
+
+	if _, ok := uc.unsynced[source]; !ok {
+		return
+	}
+	delete(uc.unsynced, source)
+	if len(uc.unsynced) == 0 {
+		close(uc.ready)
+	}
 }
 
 func (uc *UsersUseCase) GetUser(ctx context.Context, userHash string, includePII bool) (*models.User, error) {
@@ -46,7 +196,9 @@ func (uc *UsersUseCase) GetUser(ctx context.Context, userHash string, includePII
 	return user, nil
 }
 
-func (uc *UsersUseCase) ListUsers(ctx context.Context, includePII bool) (<-chan models.User, <-chan error) {
+// ListUsers streams stored users matching filter. Empty filter fields match
+// every user.
+func (uc *UsersUseCase) ListUsers(ctx context.Context, includePII bool, filter models.Filter) (<-chan models.User, <-chan error) {
 	usersCh, storageErrCh := uc.storage.ListUsers(ctx)
 
 	outCh := make(chan models.User)
@@ -73,6 +225,10 @@ func (uc *UsersUseCase) ListUsers(ctx context.Context, includePII bool) (<-chan
 					return
 				}
 
+				if !matchesFilter(user, filter) {
+					continue
+				}
+
 				if !includePII {
 					user.PII = nil
 				}
@@ -90,7 +246,28 @@ func (uc *UsersUseCase) ListUsers(ctx context.Context, includePII bool) (<-chan
 	return outCh, errCh
 }
 
-func HashUserID(sourceID string) string {
-	hash := sha256.Sum256([]byte(sourceID))
+func matchesFilter(user models.User, filter models.Filter) bool {
+	if len(filter.AccountTypes) > 0 && !slices.Contains(filter.AccountTypes, user.AccountType) {
+		return false
+	}
+
+	if len(filter.Usernames) > 0 && (user.PII == nil || !slices.Contains(filter.Usernames, user.PII.Username)) {
+		return false
+	}
+
+	return true
+}
+
+// HashGroupID derives the stable group hash, scoped to the source like
+// HashUserID.
+func HashGroupID(source, sourceID string) string {
+	hash := sha256.Sum256([]byte("group\x00" + source + "\x00" + sourceID))
+	return hex.EncodeToString(hash[:])
+}
+
+// HashUserID derives the stable user hash. The source name is part of the
+// input so that equal IDs from different identity providers never collide.
+func HashUserID(source, sourceID string) string {
+	hash := sha256.Sum256([]byte(source + "\x00" + sourceID))
 	return hex.EncodeToString(hash[:])
 }
@@ -0,0 +1,1076 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: admin/admin.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SyncMode int32
+
+const (
+	SyncMode_SYNC_MODE_UNSPECIFIED SyncMode = 0
+	SyncMode_SYNC_MODE_FULL        SyncMode = 1
+	SyncMode_SYNC_MODE_INCREMENTAL SyncMode = 2
+)
+
+// Enum value maps for SyncMode.
+var (
+	SyncMode_name = map[int32]string{
+		0: "SYNC_MODE_UNSPECIFIED",
+		1: "SYNC_MODE_FULL",
+		2: "SYNC_MODE_INCREMENTAL",
+	}
+	SyncMode_value = map[string]int32{
+		"SYNC_MODE_UNSPECIFIED": 0,
+		"SYNC_MODE_FULL":        1,
+		"SYNC_MODE_INCREMENTAL": 2,
+	}
+)
+
+func (x SyncMode) Enum() *SyncMode {
+	p := new(SyncMode)
+	*p = x
+	return p
+}
+
+func (x SyncMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SyncMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_admin_admin_proto_enumTypes[0].Descriptor()
+}
+
+func (SyncMode) Type() protoreflect.EnumType {
+	return &file_admin_admin_proto_enumTypes[0]
+}
+
+func (x SyncMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SyncMode.Descriptor instead.
+func (SyncMode) EnumDescriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type ChangeAction int32
+
+const (
+	ChangeAction_CHANGE_ACTION_UNSPECIFIED ChangeAction = 0
+	ChangeAction_CHANGE_ACTION_CREATE      ChangeAction = 1
+	ChangeAction_CHANGE_ACTION_UPDATE      ChangeAction = 2
+	ChangeAction_CHANGE_ACTION_DELETE      ChangeAction = 3
+)
+
+// Enum value maps for ChangeAction.
+var (
+	ChangeAction_name = map[int32]string{
+		0: "CHANGE_ACTION_UNSPECIFIED",
+		1: "CHANGE_ACTION_CREATE",
+		2: "CHANGE_ACTION_UPDATE",
+		3: "CHANGE_ACTION_DELETE",
+	}
+	ChangeAction_value = map[string]int32{
+		"CHANGE_ACTION_UNSPECIFIED": 0,
+		"CHANGE_ACTION_CREATE":      1,
+		"CHANGE_ACTION_UPDATE":      2,
+		"CHANGE_ACTION_DELETE":      3,
+	}
+)
+
+func (x ChangeAction) Enum() *ChangeAction {
+	p := new(ChangeAction)
+	*p = x
+	return p
+}
+
+func (x ChangeAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChangeAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_admin_admin_proto_enumTypes[1].Descriptor()
+}
+
+func (ChangeAction) Type() protoreflect.EnumType {
+	return &file_admin_admin_proto_enumTypes[1]
+}
+
+func (x ChangeAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChangeAction.Descriptor instead.
+func (ChangeAction) EnumDescriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{1}
+}
+
+type SyncRunState int32
+
+const (
+	SyncRunState_SYNC_RUN_STATE_UNSPECIFIED SyncRunState = 0
+	SyncRunState_SYNC_RUN_STATE_RUNNING     SyncRunState = 1
+	SyncRunState_SYNC_RUN_STATE_SUCCEEDED   SyncRunState = 2
+	SyncRunState_SYNC_RUN_STATE_FAILED      SyncRunState = 3
+	SyncRunState_SYNC_RUN_STATE_QUEUED      SyncRunState = 4 // triggered, waiting for the sync in progress to end
+)
+
+// Enum value maps for SyncRunState.
+var (
+	SyncRunState_name = map[int32]string{
+		0: "SYNC_RUN_STATE_UNSPECIFIED",
+		1: "SYNC_RUN_STATE_RUNNING",
+		2: "SYNC_RUN_STATE_SUCCEEDED",
+		3: "SYNC_RUN_STATE_FAILED",
+		4: "SYNC_RUN_STATE_QUEUED",
+	}
+	SyncRunState_value = map[string]int32{
+		"SYNC_RUN_STATE_UNSPECIFIED": 0,
+		"SYNC_RUN_STATE_RUNNING":     1,
+		"SYNC_RUN_STATE_SUCCEEDED":   2,
+		"SYNC_RUN_STATE_FAILED":      3,
+		"SYNC_RUN_STATE_QUEUED":      4,
+	}
+)
+
+func (x SyncRunState) Enum() *SyncRunState {
+	p := new(SyncRunState)
+	*p = x
+	return p
+}
+
+func (x SyncRunState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SyncRunState) Descriptor() protoreflect.EnumDescriptor {
+	return file_admin_admin_proto_enumTypes[2].Descriptor()
+}
+
+func (SyncRunState) Type() protoreflect.EnumType {
+	return &file_admin_admin_proto_enumTypes[2]
+}
+
+func (x SyncRunState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SyncRunState.Descriptor instead.
+func (SyncRunState) EnumDescriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{2}
+}
+
+type GetSyncStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // all sources when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSyncStatusRequest) Reset() {
+	*x = GetSyncStatusRequest{}
+	mi := &file_admin_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSyncStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSyncStatusRequest) ProtoMessage() {}
+
+func (x *GetSyncStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSyncStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetSyncStatusRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetSyncStatusRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type GetSyncStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sources       []*SourceSyncStatus    `protobuf:"bytes,1,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSyncStatusResponse) Reset() {
+	*x = GetSyncStatusResponse{}
+	mi := &file_admin_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSyncStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSyncStatusResponse) ProtoMessage() {}
+
+func (x *GetSyncStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSyncStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetSyncStatusResponse) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSyncStatusResponse) GetSources() []*SourceSyncStatus {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type SourceSyncStatus struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Source              string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	LastSuccessTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_success_time,json=lastSuccessTime,proto3" json:"last_success_time,omitempty"` // when the directory was last refreshed
+	LastFailureTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_failure_time,json=lastFailureTime,proto3" json:"last_failure_time,omitempty"`
+	LastError           string                 `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	ConsecutiveFailures int32                  `protobuf:"varint,5,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	Alert               bool                   `protobuf:"varint,6,opt,name=alert,proto3" json:"alert,omitempty"`                                       // a permanent failure stopped scheduled syncs
+	NextRetryTime       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=next_retry_time,json=nextRetryTime,proto3" json:"next_retry_time,omitempty"` // set while a failed sync waits for its backoff
+	LastRun             *SyncRun               `protobuf:"bytes,8,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SourceSyncStatus) Reset() {
+	*x = SourceSyncStatus{}
+	mi := &file_admin_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceSyncStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceSyncStatus) ProtoMessage() {}
+
+func (x *SourceSyncStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceSyncStatus.ProtoReflect.Descriptor instead.
+func (*SourceSyncStatus) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SourceSyncStatus) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SourceSyncStatus) GetLastSuccessTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSuccessTime
+	}
+	return nil
+}
+
+func (x *SourceSyncStatus) GetLastFailureTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFailureTime
+	}
+	return nil
+}
+
+func (x *SourceSyncStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *SourceSyncStatus) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *SourceSyncStatus) GetAlert() bool {
+	if x != nil {
+		return x.Alert
+	}
+	return false
+}
+
+func (x *SourceSyncStatus) GetNextRetryTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRetryTime
+	}
+	return nil
+}
+
+func (x *SourceSyncStatus) GetLastRun() *SyncRun {
+	if x != nil {
+		return x.LastRun
+	}
+	return nil
+}
+
+type ListSyncRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`                      // all sources when empty
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // defaults to 50, at most 500
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSyncRunsRequest) Reset() {
+	*x = ListSyncRunsRequest{}
+	mi := &file_admin_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSyncRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSyncRunsRequest) ProtoMessage() {}
+
+func (x *ListSyncRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSyncRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListSyncRunsRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListSyncRunsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListSyncRunsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSyncRunsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListSyncRunsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          []*SyncRun             `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"` // newest first
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSyncRunsResponse) Reset() {
+	*x = ListSyncRunsResponse{}
+	mi := &file_admin_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSyncRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSyncRunsResponse) ProtoMessage() {}
+
+func (x *ListSyncRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSyncRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListSyncRunsResponse) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListSyncRunsResponse) GetRuns() []*SyncRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+func (x *ListSyncRunsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetSyncRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSyncRunRequest) Reset() {
+	*x = GetSyncRunRequest{}
+	mi := &file_admin_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSyncRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSyncRunRequest) ProtoMessage() {}
+
+func (x *GetSyncRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSyncRunRequest.ProtoReflect.Descriptor instead.
+func (*GetSyncRunRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetSyncRunRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+type TriggerSyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Mode          SyncMode               `protobuf:"varint,2,opt,name=mode,proto3,enum=admin.SyncMode" json:"mode,omitempty"` // incremental when unspecified
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerSyncRequest) Reset() {
+	*x = TriggerSyncRequest{}
+	mi := &file_admin_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerSyncRequest) ProtoMessage() {}
+
+func (x *TriggerSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerSyncRequest.ProtoReflect.Descriptor instead.
+func (*TriggerSyncRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TriggerSyncRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *TriggerSyncRequest) GetMode() SyncMode {
+	if x != nil {
+		return x.Mode
+	}
+	return SyncMode_SYNC_MODE_UNSPECIFIED
+}
+
+type PreviewSyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	MaxChanges    int32                  `protobuf:"varint,2,opt,name=max_changes,json=maxChanges,proto3" json:"max_changes,omitempty"` // defaults to 1000, at most 10000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PreviewSyncRequest) Reset() {
+	*x = PreviewSyncRequest{}
+	mi := &file_admin_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreviewSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewSyncRequest) ProtoMessage() {}
+
+func (x *PreviewSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewSyncRequest.ProtoReflect.Descriptor instead.
+func (*PreviewSyncRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PreviewSyncRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *PreviewSyncRequest) GetMaxChanges() int32 {
+	if x != nil {
+		return x.MaxChanges
+	}
+	return 0
+}
+
+type SyncPreview struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Created       int32                  `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Updated       int32                  `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	Deleted       int32                  `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Unchanged     int32                  `protobuf:"varint,5,opt,name=unchanged,proto3" json:"unchanged,omitempty"`
+	Changes       []*UserChange          `protobuf:"bytes,6,rep,name=changes,proto3" json:"changes,omitempty"`      // ordered by action and user hash
+	Truncated     bool                   `protobuf:"varint,7,opt,name=truncated,proto3" json:"truncated,omitempty"` // more changes than max_changes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncPreview) Reset() {
+	*x = SyncPreview{}
+	mi := &file_admin_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPreview) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPreview) ProtoMessage() {}
+
+func (x *SyncPreview) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPreview.ProtoReflect.Descriptor instead.
+func (*SyncPreview) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SyncPreview) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SyncPreview) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *SyncPreview) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *SyncPreview) GetDeleted() int32 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *SyncPreview) GetUnchanged() int32 {
+	if x != nil {
+		return x.Unchanged
+	}
+	return 0
+}
+
+func (x *SyncPreview) GetChanges() []*UserChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *SyncPreview) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type UserChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserHash      string                 `protobuf:"bytes,1,opt,name=user_hash,json=userHash,proto3" json:"user_hash,omitempty"`
+	Action        ChangeAction           `protobuf:"varint,2,opt,name=action,proto3,enum=admin.ChangeAction" json:"action,omitempty"`
+	Fields        []string               `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"` // names of the changed fields of an update, e.g. "status", "pii.title"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserChange) Reset() {
+	*x = UserChange{}
+	mi := &file_admin_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserChange) ProtoMessage() {}
+
+func (x *UserChange) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserChange.ProtoReflect.Descriptor instead.
+func (*UserChange) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UserChange) GetUserHash() string {
+	if x != nil {
+		return x.UserHash
+	}
+	return ""
+}
+
+func (x *UserChange) GetAction() ChangeAction {
+	if x != nil {
+		return x.Action
+	}
+	return ChangeAction_CHANGE_ACTION_UNSPECIFIED
+}
+
+func (x *UserChange) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type SyncRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Mode          SyncMode               `protobuf:"varint,3,opt,name=mode,proto3,enum=admin.SyncMode" json:"mode,omitempty"`
+	State         SyncRunState           `protobuf:"varint,4,opt,name=state,proto3,enum=admin.SyncRunState" json:"state,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	Created       int32                  `protobuf:"varint,8,opt,name=created,proto3" json:"created,omitempty"`
+	Updated       int32                  `protobuf:"varint,9,opt,name=updated,proto3" json:"updated,omitempty"`
+	Deleted       int32                  `protobuf:"varint,10,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Unchanged     int32                  `protobuf:"varint,11,opt,name=unchanged,proto3" json:"unchanged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRun) Reset() {
+	*x = SyncRun{}
+	mi := &file_admin_admin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRun) ProtoMessage() {}
+
+func (x *SyncRun) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRun.ProtoReflect.Descriptor instead.
+func (*SyncRun) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SyncRun) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *SyncRun) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SyncRun) GetMode() SyncMode {
+	if x != nil {
+		return x.Mode
+	}
+	return SyncMode_SYNC_MODE_UNSPECIFIED
+}
+
+func (x *SyncRun) GetState() SyncRunState {
+	if x != nil {
+		return x.State
+	}
+	return SyncRunState_SYNC_RUN_STATE_UNSPECIFIED
+}
+
+func (x *SyncRun) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *SyncRun) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *SyncRun) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SyncRun) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *SyncRun) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *SyncRun) GetDeleted() int32 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *SyncRun) GetUnchanged() int32 {
+	if x != nil {
+		return x.Unchanged
+	}
+	return 0
+}
+
+var File_admin_admin_proto protoreflect.FileDescriptor
+
+const file_admin_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x11admin/admin.proto\x12\x05admin\x1a\x1fgoogle/protobuf/timestamp.proto\".\n" +
+	"\x14GetSyncStatusRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\"J\n" +
+	"\x15GetSyncStatusResponse\x121\n" +
+	"\asources\x18\x01 \x03(\v2\x17.admin.SourceSyncStatusR\asources\"\x91\x03\n" +
+	"\x10SourceSyncStatus\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12F\n" +
+	"\x11last_success_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x0flastSuccessTime\x12F\n" +
+	"\x11last_failure_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x0flastFailureTime\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x04 \x01(\tR\tlastError\x121\n" +
+	"\x14consecutive_failures\x18\x05 \x01(\x05R\x13consecutiveFailures\x12\x14\n" +
+	"\x05alert\x18\x06 \x01(\bR\x05alert\x12B\n" +
+	"\x0fnext_retry_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\rnextRetryTime\x12)\n" +
+	"\blast_run\x18\b \x01(\v2\x0e.admin.SyncRunR\alastRun\"i\n" +
+	"\x13ListSyncRunsRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"b\n" +
+	"\x14ListSyncRunsResponse\x12\"\n" +
+	"\x04runs\x18\x01 \x03(\v2\x0e.admin.SyncRunR\x04runs\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"*\n" +
+	"\x11GetSyncRunRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\"Q\n" +
+	"\x12TriggerSyncRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12#\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x0f.admin.SyncModeR\x04mode\"M\n" +
+	"\x12PreviewSyncRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x1f\n" +
+	"\vmax_changes\x18\x02 \x01(\x05R\n" +
+	"maxChanges\"\xdc\x01\n" +
+	"\vSyncPreview\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\x05R\acreated\x12\x18\n" +
+	"\aupdated\x18\x03 \x01(\x05R\aupdated\x12\x18\n" +
+	"\adeleted\x18\x04 \x01(\x05R\adeleted\x12\x1c\n" +
+	"\tunchanged\x18\x05 \x01(\x05R\tunchanged\x12+\n" +
+	"\achanges\x18\x06 \x03(\v2\x11.admin.UserChangeR\achanges\x12\x1c\n" +
+	"\ttruncated\x18\a \x01(\bR\ttruncated\"n\n" +
+	"\n" +
+	"UserChange\x12\x1b\n" +
+	"\tuser_hash\x18\x01 \x01(\tR\buserHash\x12+\n" +
+	"\x06action\x18\x02 \x01(\x0e2\x13.admin.ChangeActionR\x06action\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\"\xfc\x02\n" +
+	"\aSyncRun\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12#\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x0f.admin.SyncModeR\x04mode\x12)\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x13.admin.SyncRunStateR\x05state\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x18\n" +
+	"\acreated\x18\b \x01(\x05R\acreated\x12\x18\n" +
+	"\aupdated\x18\t \x01(\x05R\aupdated\x12\x18\n" +
+	"\adeleted\x18\n" +
+	" \x01(\x05R\adeleted\x12\x1c\n" +
+	"\tunchanged\x18\v \x01(\x05R\tunchanged*T\n" +
+	"\bSyncMode\x12\x19\n" +
+	"\x15SYNC_MODE_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eSYNC_MODE_FULL\x10\x01\x12\x19\n" +
+	"\x15SYNC_MODE_INCREMENTAL\x10\x02*{\n" +
+	"\fChangeAction\x12\x1d\n" +
+	"\x19CHANGE_ACTION_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14CHANGE_ACTION_CREATE\x10\x01\x12\x18\n" +
+	"\x14CHANGE_ACTION_UPDATE\x10\x02\x12\x18\n" +
+	"\x14CHANGE_ACTION_DELETE\x10\x03*\x9e\x01\n" +
+	"\fSyncRunState\x12\x1e\n" +
+	"\x1aSYNC_RUN_STATE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16SYNC_RUN_STATE_RUNNING\x10\x01\x12\x1c\n" +
+	"\x18SYNC_RUN_STATE_SUCCEEDED\x10\x02\x12\x19\n" +
+	"\x15SYNC_RUN_STATE_FAILED\x10\x03\x12\x19\n" +
+	"\x15SYNC_RUN_STATE_QUEUED\x10\x042\xd3\x02\n" +
+	"\fAdminService\x12J\n" +
+	"\rGetSyncStatus\x12\x1b.admin.GetSyncStatusRequest\x1a\x1c.admin.GetSyncStatusResponse\x12G\n" +
+	"\fListSyncRuns\x12\x1a.admin.ListSyncRunsRequest\x1a\x1b.admin.ListSyncRunsResponse\x126\n" +
+	"\n" +
+	"GetSyncRun\x12\x18.admin.GetSyncRunRequest\x1a\x0e.admin.SyncRun\x128\n" +
+	"\vTriggerSync\x12\x19.admin.TriggerSyncRequest\x1a\x0e.admin.SyncRun\x12<\n" +
+	"\vPreviewSync\x12\x19.admin.PreviewSyncRequest\x1a\x12.admin.SyncPreviewB\bZ\x06pkg/pbb\x06proto3"
+
+var (
+	file_admin_admin_proto_rawDescOnce sync.Once
+	file_admin_admin_proto_rawDescData []byte
+)
+
+func file_admin_admin_proto_rawDescGZIP() []byte {
+	file_admin_admin_proto_rawDescOnce.Do(func() {
+		file_admin_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_admin_admin_proto_rawDesc), len(file_admin_admin_proto_rawDesc)))
+	})
+	return file_admin_admin_proto_rawDescData
+}
+
+var file_admin_admin_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_admin_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_admin_admin_proto_goTypes = []any{
+	(SyncMode)(0),                 // 0: admin.SyncMode
+	(ChangeAction)(0),             // 1: admin.ChangeAction
+	(SyncRunState)(0),             // 2: admin.SyncRunState
+	(*GetSyncStatusRequest)(nil),  // 3: admin.GetSyncStatusRequest
+	(*GetSyncStatusResponse)(nil), // 4: admin.GetSyncStatusResponse
+	(*SourceSyncStatus)(nil),      // 5: admin.SourceSyncStatus
+	(*ListSyncRunsRequest)(nil),   // 6: admin.ListSyncRunsRequest
+	(*ListSyncRunsResponse)(nil),  // 7: admin.ListSyncRunsResponse
+	(*GetSyncRunRequest)(nil),     // 8: admin.GetSyncRunRequest
+	(*TriggerSyncRequest)(nil),    // 9: admin.TriggerSyncRequest
+	(*PreviewSyncRequest)(nil),    // 10: admin.PreviewSyncRequest
+	(*SyncPreview)(nil),           // 11: admin.SyncPreview
+	(*UserChange)(nil),            // 12: admin.UserChange
+	(*SyncRun)(nil),               // 13: admin.SyncRun
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+}
+var file_admin_admin_proto_depIdxs = []int32{
+	5,  // 0: admin.GetSyncStatusResponse.sources:type_name -> admin.SourceSyncStatus
+	14, // 1: admin.SourceSyncStatus.last_success_time:type_name -> google.protobuf.Timestamp
+	14, // 2: admin.SourceSyncStatus.last_failure_time:type_name -> google.protobuf.Timestamp
+	14, // 3: admin.SourceSyncStatus.next_retry_time:type_name -> google.protobuf.Timestamp
+	13, // 4: admin.SourceSyncStatus.last_run:type_name -> admin.SyncRun
+	13, // 5: admin.ListSyncRunsResponse.runs:type_name -> admin.SyncRun
+	0,  // 6: admin.TriggerSyncRequest.mode:type_name -> admin.SyncMode
+	12, // 7: admin.SyncPreview.changes:type_name -> admin.UserChange
+	1,  // 8: admin.UserChange.action:type_name -> admin.ChangeAction
+	0,  // 9: admin.SyncRun.mode:type_name -> admin.SyncMode
+	2,  // 10: admin.SyncRun.state:type_name -> admin.SyncRunState
+	14, // 11: admin.SyncRun.start_time:type_name -> google.protobuf.Timestamp
+	14, // 12: admin.SyncRun.end_time:type_name -> google.protobuf.Timestamp
+	3,  // 13: admin.AdminService.GetSyncStatus:input_type -> admin.GetSyncStatusRequest
+	6,  // 14: admin.AdminService.ListSyncRuns:input_type -> admin.ListSyncRunsRequest
+	8,  // 15: admin.AdminService.GetSyncRun:input_type -> admin.GetSyncRunRequest
+	9,  // 16: admin.AdminService.TriggerSync:input_type -> admin.TriggerSyncRequest
+	10, // 17: admin.AdminService.PreviewSync:input_type -> admin.PreviewSyncRequest
+	4,  // 18: admin.AdminService.GetSyncStatus:output_type -> admin.GetSyncStatusResponse
+	7,  // 19: admin.AdminService.ListSyncRuns:output_type -> admin.ListSyncRunsResponse
+	13, // 20: admin.AdminService.GetSyncRun:output_type -> admin.SyncRun
+	13, // 21: admin.AdminService.TriggerSync:output_type -> admin.SyncRun
+	11, // 22: admin.AdminService.PreviewSync:output_type -> admin.SyncPreview
+	18, // [18:23] is the sub-list for method output_type
+	13, // [13:18] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_admin_admin_proto_init() }
+func file_admin_admin_proto_init() {
+	if File_admin_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_admin_admin_proto_rawDesc), len(file_admin_admin_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_admin_admin_proto_goTypes,
+		DependencyIndexes: file_admin_admin_proto_depIdxs,
+		EnumInfos:         file_admin_admin_proto_enumTypes,
+		MessageInfos:      file_admin_admin_proto_msgTypes,
+	}.Build()
+	File_admin_admin_proto = out.File
+	file_admin_admin_proto_goTypes = nil
+	file_admin_admin_proto_depIdxs = nil
+}
@@ -9,6 +9,7 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,12 +22,69 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type UserChangeType int32
+
+const (
+	UserChangeType_USER_CHANGE_TYPE_UNSPECIFIED UserChangeType = 0
+	UserChangeType_USER_CHANGE_TYPE_CREATED     UserChangeType = 1
+	UserChangeType_USER_CHANGE_TYPE_UPDATED     UserChangeType = 2
+	UserChangeType_USER_CHANGE_TYPE_DELETED     UserChangeType = 3
+)
+
+// Enum value maps for UserChangeType.
+var (
+	UserChangeType_name = map[int32]string{
+		0: "USER_CHANGE_TYPE_UNSPECIFIED",
+		1: "USER_CHANGE_TYPE_CREATED",
+		2: "USER_CHANGE_TYPE_UPDATED",
+		3: "USER_CHANGE_TYPE_DELETED",
+	}
+	UserChangeType_value = map[string]int32{
+		"USER_CHANGE_TYPE_UNSPECIFIED": 0,
+		"USER_CHANGE_TYPE_CREATED":     1,
+		"USER_CHANGE_TYPE_UPDATED":     2,
+		"USER_CHANGE_TYPE_DELETED":     3,
+	}
+)
+
+func (x UserChangeType) Enum() *UserChangeType {
+	p := new(UserChangeType)
+	*p = x
+	return p
+}
+
+func (x UserChangeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UserChangeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_users_users_proto_enumTypes[0].Descriptor()
+}
+
+func (UserChangeType) Type() protoreflect.EnumType {
+	return &file_users_users_proto_enumTypes[0]
+}
+
+func (x UserChangeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UserChangeType.Descriptor instead.
+func (UserChangeType) EnumDescriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{0}
+}
+
 type UserStatus int32
 
 const (
-	UserStatus_USER_STATUS_UNSPECIFIED UserStatus = 0
-	UserStatus_USER_STATUS_ACTIVE      UserStatus = 1
-	UserStatus_USER_STATUS_DISABLED    UserStatus = 2
+	UserStatus_USER_STATUS_UNSPECIFIED      UserStatus = 0
+	UserStatus_USER_STATUS_ACTIVE           UserStatus = 1
+	UserStatus_USER_STATUS_DISABLED         UserStatus = 2
+	UserStatus_USER_STATUS_LOCKED           UserStatus = 3
+	UserStatus_USER_STATUS_EXPIRED          UserStatus = 4 // account expiry date has passed
+	UserStatus_USER_STATUS_PASSWORD_EXPIRED UserStatus = 5 // password must be changed before sign-in
+	UserStatus_USER_STATUS_PENDING          UserStatus = 6 // not valid yet, e.g. a future start date
+	UserStatus_USER_STATUS_DELETED          UserStatus = 7
 )
 
 // Enum value maps for UserStatus.
@@ -35,11 +93,21 @@ var (
 		0: "USER_STATUS_UNSPECIFIED",
 		1: "USER_STATUS_ACTIVE",
 		2: "USER_STATUS_DISABLED",
+		3: "USER_STATUS_LOCKED",
+		4: "USER_STATUS_EXPIRED",
+		5: "USER_STATUS_PASSWORD_EXPIRED",
+		6: "USER_STATUS_PENDING",
+		7: "USER_STATUS_DELETED",
 	}
 	UserStatus_value = map[string]int32{
-		"USER_STATUS_UNSPECIFIED": 0,
-		"USER_STATUS_ACTIVE":      1,
-		"USER_STATUS_DISABLED":    2,
+		"USER_STATUS_UNSPECIFIED":      0,
+		"USER_STATUS_ACTIVE":           1,
+		"USER_STATUS_DISABLED":         2,
+		"USER_STATUS_LOCKED":           3,
+		"USER_STATUS_EXPIRED":          4,
+		"USER_STATUS_PASSWORD_EXPIRED": 5,
+		"USER_STATUS_PENDING":          6,
+		"USER_STATUS_DELETED":          7,
 	}
 )
 
@@ -54,11 +122,11 @@ func (x UserStatus) String() string {
 }
 
 func (UserStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_users_users_proto_enumTypes[0].Descriptor()
+	return file_users_users_proto_enumTypes[1].Descriptor()
 }
 
 func (UserStatus) Type() protoreflect.EnumType {
-	return &file_users_users_proto_enumTypes[0]
+	return &file_users_users_proto_enumTypes[1]
 }
 
 func (x UserStatus) Number() protoreflect.EnumNumber {
@@ -67,22 +135,25 @@ func (x UserStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use UserStatus.Descriptor instead.
 func (UserStatus) EnumDescriptor() ([]byte, []int) {
-	return file_users_users_proto_rawDescGZIP(), []int{0}
+	return file_users_users_proto_rawDescGZIP(), []int{1}
 }
 
 type AttributeKey int32
 
 const (
-	AttributeKey_ATTRIBUTE_KEY_UNSPECIFIED AttributeKey = 0
+	AttributeKey_ATTRIBUTE_KEY_UNSPECIFIED        AttributeKey = 0
+	AttributeKey_ATTRIBUTE_KEY_DISTINGUISHED_NAME AttributeKey = 1
 )
 
 // Enum value maps for AttributeKey.
 var (
 	AttributeKey_name = map[int32]string{
 		0: "ATTRIBUTE_KEY_UNSPECIFIED",
+		1: "ATTRIBUTE_KEY_DISTINGUISHED_NAME",
 	}
 	AttributeKey_value = map[string]int32{
-		"ATTRIBUTE_KEY_UNSPECIFIED": 0,
+		"ATTRIBUTE_KEY_UNSPECIFIED":        0,
+		"ATTRIBUTE_KEY_DISTINGUISHED_NAME": 1,
 	}
 )
 
@@ -97,11 +168,11 @@ func (x AttributeKey) String() string {
 }
 
 func (AttributeKey) Descriptor() protoreflect.EnumDescriptor {
-	return file_users_users_proto_enumTypes[1].Descriptor()
+	return file_users_users_proto_enumTypes[2].Descriptor()
 }
 
 func (AttributeKey) Type() protoreflect.EnumType {
-	return &file_users_users_proto_enumTypes[1]
+	return &file_users_users_proto_enumTypes[2]
 }
 
 func (x AttributeKey) Number() protoreflect.EnumNumber {
@@ -110,7 +181,62 @@ func (x AttributeKey) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use AttributeKey.Descriptor instead.
 func (AttributeKey) EnumDescriptor() ([]byte, []int) {
-	return file_users_users_proto_rawDescGZIP(), []int{1}
+	return file_users_users_proto_rawDescGZIP(), []int{2}
+}
+
+type AccountType int32
+
+const (
+	AccountType_ACCOUNT_TYPE_UNSPECIFIED AccountType = 0
+	AccountType_ACCOUNT_TYPE_HUMAN       AccountType = 1
+	AccountType_ACCOUNT_TYPE_SERVICE     AccountType = 2
+	AccountType_ACCOUNT_TYPE_SHARED      AccountType = 3 // shared mailboxes, rooms, team logins
+	AccountType_ACCOUNT_TYPE_PRIVILEGED  AccountType = 4
+)
+
+// Enum value maps for AccountType.
+var (
+	AccountType_name = map[int32]string{
+		0: "ACCOUNT_TYPE_UNSPECIFIED",
+		1: "ACCOUNT_TYPE_HUMAN",
+		2: "ACCOUNT_TYPE_SERVICE",
+		3: "ACCOUNT_TYPE_SHARED",
+		4: "ACCOUNT_TYPE_PRIVILEGED",
+	}
+	AccountType_value = map[string]int32{
+		"ACCOUNT_TYPE_UNSPECIFIED": 0,
+		"ACCOUNT_TYPE_HUMAN":       1,
+		"ACCOUNT_TYPE_SERVICE":     2,
+		"ACCOUNT_TYPE_SHARED":      3,
+		"ACCOUNT_TYPE_PRIVILEGED":  4,
+	}
+)
+
+func (x AccountType) Enum() *AccountType {
+	p := new(AccountType)
+	*p = x
+	return p
+}
+
+func (x AccountType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccountType) Descriptor() protoreflect.EnumDescriptor {
+	return file_users_users_proto_enumTypes[3].Descriptor()
+}
+
+func (AccountType) Type() protoreflect.EnumType {
+	return &file_users_users_proto_enumTypes[3]
+}
+
+func (x AccountType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccountType.Descriptor instead.
+func (AccountType) EnumDescriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{3}
 }
 
 type IdentityProviderType int32
@@ -119,6 +245,11 @@ const (
 	IdentityProviderType_IDENTITY_PROVIDER_TYPE_UNSPECIFIED      IdentityProviderType = 0
 	IdentityProviderType_IDENTITY_PROVIDER_TYPE_ACTIVE_DIRECTORY IdentityProviderType = 1
 	IdentityProviderType_IDENTITY_PROVIDER_TYPE_LDAP             IdentityProviderType = 2
+	IdentityProviderType_IDENTITY_PROVIDER_TYPE_SCIM             IdentityProviderType = 3
+	IdentityProviderType_IDENTITY_PROVIDER_TYPE_ENTRA_ID         IdentityProviderType = 4
+	IdentityProviderType_IDENTITY_PROVIDER_TYPE_GOOGLE_WORKSPACE IdentityProviderType = 5
+	IdentityProviderType_IDENTITY_PROVIDER_TYPE_FILE             IdentityProviderType = 6
+	IdentityProviderType_IDENTITY_PROVIDER_TYPE_HR               IdentityProviderType = 7
 )
 
 // Enum value maps for IdentityProviderType.
@@ -127,11 +258,21 @@ var (
 		0: "IDENTITY_PROVIDER_TYPE_UNSPECIFIED",
 		1: "IDENTITY_PROVIDER_TYPE_ACTIVE_DIRECTORY",
 		2: "IDENTITY_PROVIDER_TYPE_LDAP",
+		3: "IDENTITY_PROVIDER_TYPE_SCIM",
+		4: "IDENTITY_PROVIDER_TYPE_ENTRA_ID",
+		5: "IDENTITY_PROVIDER_TYPE_GOOGLE_WORKSPACE",
+		6: "IDENTITY_PROVIDER_TYPE_FILE",
+		7: "IDENTITY_PROVIDER_TYPE_HR",
 	}
 	IdentityProviderType_value = map[string]int32{
 		"IDENTITY_PROVIDER_TYPE_UNSPECIFIED":      0,
 		"IDENTITY_PROVIDER_TYPE_ACTIVE_DIRECTORY": 1,
 		"IDENTITY_PROVIDER_TYPE_LDAP":             2,
+		"IDENTITY_PROVIDER_TYPE_SCIM":             3,
+		"IDENTITY_PROVIDER_TYPE_ENTRA_ID":         4,
+		"IDENTITY_PROVIDER_TYPE_GOOGLE_WORKSPACE": 5,
+		"IDENTITY_PROVIDER_TYPE_FILE":             6,
+		"IDENTITY_PROVIDER_TYPE_HR":               7,
 	}
 )
 
@@ -146,11 +287,11 @@ func (x IdentityProviderType) String() string {
 }
 
 func (IdentityProviderType) Descriptor() protoreflect.EnumDescriptor {
-	return file_users_users_proto_enumTypes[2].Descriptor()
+	return file_users_users_proto_enumTypes[4].Descriptor()
 }
 
 func (IdentityProviderType) Type() protoreflect.EnumType {
-	return &file_users_users_proto_enumTypes[2]
+	return &file_users_users_proto_enumTypes[4]
 }
 
 func (x IdentityProviderType) Number() protoreflect.EnumNumber {
@@ -159,12 +300,17 @@ func (x IdentityProviderType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use IdentityProviderType.Descriptor instead.
 func (IdentityProviderType) EnumDescriptor() ([]byte, []int) {
-	return file_users_users_proto_rawDescGZIP(), []int{2}
+	return file_users_users_proto_rawDescGZIP(), []int{4}
 }
 
 type ListUsersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	IncludePii    bool                   `protobuf:"varint,1,opt,name=include_pii,json=includePii,proto3" json:"include_pii,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	IncludePii   bool                   `protobuf:"varint,1,opt,name=include_pii,json=includePii,proto3" json:"include_pii,omitempty"`
+	AccountTypes []AccountType          `protobuf:"varint,2,rep,packed,name=account_types,json=accountTypes,proto3,enum=users.AccountType" json:"account_types,omitempty"` // return only these types; all when empty
+	// Return the users as stored at this time, rebuilt from the user history.
+	// FAILED_PRECONDITION when history is not enabled or the time precedes when
+	// it was last enabled, OUT_OF_RANGE beyond its retention.
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -206,10 +352,25 @@ func (x *ListUsersRequest) GetIncludePii() bool {
 	return false
 }
 
+func (x *ListUsersRequest) GetAccountTypes() []AccountType {
+	if x != nil {
+		return x.AccountTypes
+	}
+	return nil
+}
+
+func (x *ListUsersRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
 type GetUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserHash      string                 `protobuf:"bytes,1,opt,name=user_hash,json=userHash,proto3" json:"user_hash,omitempty"`
 	IncludePii    bool                   `protobuf:"varint,2,opt,name=include_pii,json=includePii,proto3" json:"include_pii,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"` // see ListUsersRequest.as_of
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -258,19 +419,532 @@ func (x *GetUserRequest) GetIncludePii() bool {
 	return false
 }
 
-type User struct {
+func (x *GetUserRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type RefreshUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserHash      string                 `protobuf:"bytes,1,opt,name=user_hash,json=userHash,proto3" json:"user_hash,omitempty"`
-	UserPii       *UserPII               `protobuf:"bytes,2,opt,name=user_pii,json=userPii,proto3,oneof" json:"user_pii,omitempty"`
-	Status        UserStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=users.UserStatus" json:"status,omitempty"`
-	IdpType       IdentityProviderType   `protobuf:"varint,4,opt,name=idp_type,json=idpType,proto3,enum=users.IdentityProviderType" json:"idp_type,omitempty"`
+	IncludePii    bool                   `protobuf:"varint,2,opt,name=include_pii,json=includePii,proto3" json:"include_pii,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *RefreshUserRequest) Reset() {
+	*x = RefreshUserRequest{}
+	mi := &file_users_users_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshUserRequest) ProtoMessage() {}
+
+func (x *RefreshUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshUserRequest.ProtoReflect.Descriptor instead.
+func (*RefreshUserRequest) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RefreshUserRequest) GetUserHash() string {
+	if x != nil {
+		return x.UserHash
+	}
+	return ""
+}
+
+func (x *RefreshUserRequest) GetIncludePii() bool {
+	if x != nil {
+		return x.IncludePii
+	}
+	return false
+}
+
+type GetUserHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserHash      string                 `protobuf:"bytes,1,opt,name=user_hash,json=userHash,proto3" json:"user_hash,omitempty"`
+	IncludePii    bool                   `protobuf:"varint,2,opt,name=include_pii,json=includePii,proto3" json:"include_pii,omitempty"` // decrypt the old and new values
+	Fields        []string               `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`                            // only versions changing one of these, e.g. "pii.department"
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`       // defaults to 100, at most 1000
+	PageToken     string                 `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserHistoryRequest) Reset() {
+	*x = GetUserHistoryRequest{}
+	mi := &file_users_users_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserHistoryRequest) ProtoMessage() {}
+
+func (x *GetUserHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetUserHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetUserHistoryRequest) GetUserHash() string {
+	if x != nil {
+		return x.UserHash
+	}
+	return ""
+}
+
+func (x *GetUserHistoryRequest) GetIncludePii() bool {
+	if x != nil {
+		return x.IncludePii
+	}
+	return false
+}
+
+func (x *GetUserHistoryRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *GetUserHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetUserHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type GetUserHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*UserVersion         `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserHistoryResponse) Reset() {
+	*x = GetUserHistoryResponse{}
+	mi := &file_users_users_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserHistoryResponse) ProtoMessage() {}
+
+func (x *GetUserHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetUserHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserHistoryResponse) GetVersions() []*UserVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *GetUserHistoryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UserVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChangeTime    *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=change_time,json=changeTime,proto3" json:"change_time,omitempty"`
+	ChangeType    UserChangeType         `protobuf:"varint,2,opt,name=change_type,json=changeType,proto3,enum=users.UserChangeType" json:"change_type,omitempty"`
+	Fields        []string               `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`   // JSON names of the changed fields, PII ones prefixed with "pii."
+	Changes       []*FieldChange         `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"` // only with include_pii
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserVersion) Reset() {
+	*x = UserVersion{}
+	mi := &file_users_users_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserVersion) ProtoMessage() {}
+
+func (x *UserVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserVersion.ProtoReflect.Descriptor instead.
+func (*UserVersion) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UserVersion) GetChangeTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangeTime
+	}
+	return nil
+}
+
+func (x *UserVersion) GetChangeType() UserChangeType {
+	if x != nil {
+		return x.ChangeType
+	}
+	return UserChangeType_USER_CHANGE_TYPE_UNSPECIFIED
+}
+
+func (x *UserVersion) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *UserVersion) GetChanges() []*FieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+// FieldChange holds JSON encoded values; an unset value is the field's zero
+// value.
+type FieldChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	OldValue      *string                `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3,oneof" json:"old_value,omitempty"`
+	NewValue      *string                `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3,oneof" json:"new_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_users_users_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldChange) ProtoMessage() {}
+
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FieldChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldChange) GetOldValue() string {
+	if x != nil && x.OldValue != nil {
+		return *x.OldValue
+	}
+	return ""
+}
+
+func (x *FieldChange) GetNewValue() string {
+	if x != nil && x.NewValue != nil {
+		return *x.NewValue
+	}
+	return ""
+}
+
+type GetPersonRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Lookup:
+	//
+	//	*GetPersonRequest_PersonHash
+	//	*GetPersonRequest_UserHash
+	Lookup        isGetPersonRequest_Lookup `protobuf_oneof:"lookup"`
+	IncludePii    bool                      `protobuf:"varint,3,opt,name=include_pii,json=includePii,proto3" json:"include_pii,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonRequest) Reset() {
+	*x = GetPersonRequest{}
+	mi := &file_users_users_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonRequest) ProtoMessage() {}
+
+func (x *GetPersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonRequest.ProtoReflect.Descriptor instead.
+func (*GetPersonRequest) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetPersonRequest) GetLookup() isGetPersonRequest_Lookup {
+	if x != nil {
+		return x.Lookup
+	}
+	return nil
+}
+
+func (x *GetPersonRequest) GetPersonHash() string {
+	if x != nil {
+		if x, ok := x.Lookup.(*GetPersonRequest_PersonHash); ok {
+			return x.PersonHash
+		}
+	}
+	return ""
+}
+
+func (x *GetPersonRequest) GetUserHash() string {
+	if x != nil {
+		if x, ok := x.Lookup.(*GetPersonRequest_UserHash); ok {
+			return x.UserHash
+		}
+	}
+	return ""
+}
+
+func (x *GetPersonRequest) GetIncludePii() bool {
+	if x != nil {
+		return x.IncludePii
+	}
+	return false
+}
+
+type isGetPersonRequest_Lookup interface {
+	isGetPersonRequest_Lookup()
+}
+
+type GetPersonRequest_PersonHash struct {
+	PersonHash string `protobuf:"bytes,1,opt,name=person_hash,json=personHash,proto3,oneof"`
+}
+
+type GetPersonRequest_UserHash struct {
+	UserHash string `protobuf:"bytes,2,opt,name=user_hash,json=userHash,proto3,oneof"` // resolve the person an account belongs to
+}
+
+func (*GetPersonRequest_PersonHash) isGetPersonRequest_Lookup() {}
+
+func (*GetPersonRequest_UserHash) isGetPersonRequest_Lookup() {}
+
+type ListPersonsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IncludePii    bool                   `protobuf:"varint,1,opt,name=include_pii,json=includePii,proto3" json:"include_pii,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPersonsRequest) Reset() {
+	*x = ListPersonsRequest{}
+	mi := &file_users_users_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPersonsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPersonsRequest) ProtoMessage() {}
+
+func (x *ListPersonsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPersonsRequest.ProtoReflect.Descriptor instead.
+func (*ListPersonsRequest) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListPersonsRequest) GetIncludePii() bool {
+	if x != nil {
+		return x.IncludePii
+	}
+	return false
+}
+
+// Person links the accounts of one human across identity providers.
+type Person struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PersonHash    string                 `protobuf:"bytes,1,opt,name=person_hash,json=personHash,proto3" json:"person_hash,omitempty"`
+	Accounts      []*User                `protobuf:"bytes,2,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Person) Reset() {
+	*x = Person{}
+	mi := &file_users_users_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Person) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Person) ProtoMessage() {}
+
+func (x *Person) ProtoReflect() protoreflect.Message {
+	mi := &file_users_users_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Person.ProtoReflect.Descriptor instead.
+func (*Person) Descriptor() ([]byte, []int) {
+	return file_users_users_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Person) GetPersonHash() string {
+	if x != nil {
+		return x.PersonHash
+	}
+	return ""
+}
+
+func (x *Person) GetAccounts() []*User {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+type User struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UserHash    string                 `protobuf:"bytes,1,opt,name=user_hash,json=userHash,proto3" json:"user_hash,omitempty"`
+	UserPii     *UserPII               `protobuf:"bytes,2,opt,name=user_pii,json=userPii,proto3,oneof" json:"user_pii,omitempty"`
+	Status      UserStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=users.UserStatus" json:"status,omitempty"`
+	IdpType     IdentityProviderType   `protobuf:"varint,4,opt,name=idp_type,json=idpType,proto3,enum=users.IdentityProviderType" json:"idp_type,omitempty"`
+	Source      string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"` // name of the configured identity provider
+	AccountType AccountType            `protobuf:"varint,12,opt,name=account_type,json=accountType,proto3,enum=users.AccountType" json:"account_type,omitempty"`
+	// Security signals, returned regardless of include_pii.
+	LastLogonTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_logon_time,json=lastLogonTime,proto3" json:"last_logon_time,omitempty"`                     // lastLogonTimestamp, lastLoginTime
+	PasswordLastSetTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=password_last_set_time,json=passwordLastSetTime,proto3" json:"password_last_set_time,omitempty"` // pwdLastSet, pwdChangedTime
+	CreateTime          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`                                // whenCreated, createTimestamp
+	Privileged          bool                   `protobuf:"varint,9,opt,name=privileged,proto3" json:"privileged,omitempty"`                                                 // adminCount, isAdmin
+	ServiceAccount      bool                   `protobuf:"varint,10,opt,name=service_account,json=serviceAccount,proto3" json:"service_account,omitempty"`                  // has a servicePrincipalName
+	MfaEnabled          *bool                  `protobuf:"varint,11,opt,name=mfa_enabled,json=mfaEnabled,proto3,oneof" json:"mfa_enabled,omitempty"`                        // unset when the provider does not report it
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
 func (x *User) Reset() {
 	*x = User{}
-	mi := &file_users_users_proto_msgTypes[2]
+	mi := &file_users_users_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -282,7 +956,7 @@ func (x *User) String() string {
 func (*User) ProtoMessage() {}
 
 func (x *User) ProtoReflect() protoreflect.Message {
-	mi := &file_users_users_proto_msgTypes[2]
+	mi := &file_users_users_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -295,7 +969,7 @@ func (x *User) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use User.ProtoReflect.Descriptor instead.
 func (*User) Descriptor() ([]byte, []int) {
-	return file_users_users_proto_rawDescGZIP(), []int{2}
+	return file_users_users_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *User) GetUserHash() string {
@@ -326,6 +1000,62 @@ func (x *User) GetIdpType() IdentityProviderType {
 	return IdentityProviderType_IDENTITY_PROVIDER_TYPE_UNSPECIFIED
 }
 
+func (x *User) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *User) GetAccountType() AccountType {
+	if x != nil {
+		return x.AccountType
+	}
+	return AccountType_ACCOUNT_TYPE_UNSPECIFIED
+}
+
+func (x *User) GetLastLogonTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastLogonTime
+	}
+	return nil
+}
+
+func (x *User) GetPasswordLastSetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PasswordLastSetTime
+	}
+	return nil
+}
+
+func (x *User) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *User) GetPrivileged() bool {
+	if x != nil {
+		return x.Privileged
+	}
+	return false
+}
+
+func (x *User) GetServiceAccount() bool {
+	if x != nil {
+		return x.ServiceAccount
+	}
+	return false
+}
+
+func (x *User) GetMfaEnabled() bool {
+	if x != nil && x.MfaEnabled != nil {
+		return *x.MfaEnabled
+	}
+	return false
+}
+
 type UserPII struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Username      *string                `protobuf:"bytes,1,opt,name=username,proto3,oneof" json:"username,omitempty"`                          // sAMAccountName, login, etc.
@@ -346,7 +1076,7 @@ type UserPII struct {
 
 func (x *UserPII) Reset() {
 	*x = UserPII{}
-	mi := &file_users_users_proto_msgTypes[3]
+	mi := &file_users_users_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -358,7 +1088,7 @@ func (x *UserPII) String() string {
 func (*UserPII) ProtoMessage() {}
 
 func (x *UserPII) ProtoReflect() protoreflect.Message {
-	mi := &file_users_users_proto_msgTypes[3]
+	mi := &file_users_users_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -371,7 +1101,7 @@ func (x *UserPII) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserPII.ProtoReflect.Descriptor instead.
 func (*UserPII) Descriptor() ([]byte, []int) {
-	return file_users_users_proto_rawDescGZIP(), []int{3}
+	return file_users_users_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *UserPII) GetUsername() string {
@@ -468,7 +1198,7 @@ type Attribute struct {
 
 func (x *Attribute) Reset() {
 	*x = Attribute{}
-	mi := &file_users_users_proto_msgTypes[4]
+	mi := &file_users_users_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -480,7 +1210,7 @@ func (x *Attribute) String() string {
 func (*Attribute) ProtoMessage() {}
 
 func (x *Attribute) ProtoReflect() protoreflect.Message {
-	mi := &file_users_users_proto_msgTypes[4]
+	mi := &file_users_users_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -493,7 +1223,7 @@ func (x *Attribute) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Attribute.ProtoReflect.Descriptor instead.
 func (*Attribute) Descriptor() ([]byte, []int) {
-	return file_users_users_proto_rawDescGZIP(), []int{4}
+	return file_users_users_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Attribute) GetKey() AttributeKey {
@@ -514,20 +1244,81 @@ var File_users_users_proto protoreflect.FileDescriptor
 
 const file_users_users_proto_rawDesc = "" +
 	"\n" +
-	"\x11users/users.proto\x12\x05users\"3\n" +
+	"\x11users/users.proto\x12\x05users\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9d\x01\n" +
 	"\x10ListUsersRequest\x12\x1f\n" +
 	"\vinclude_pii\x18\x01 \x01(\bR\n" +
-	"includePii\"N\n" +
+	"includePii\x127\n" +
+	"\raccount_types\x18\x02 \x03(\x0e2\x12.users.AccountTypeR\faccountTypes\x12/\n" +
+	"\x05as_of\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"\x7f\n" +
 	"\x0eGetUserRequest\x12\x1b\n" +
 	"\tuser_hash\x18\x01 \x01(\tR\buserHash\x12\x1f\n" +
 	"\vinclude_pii\x18\x02 \x01(\bR\n" +
-	"includePii\"\xc3\x01\n" +
+	"includePii\x12/\n" +
+	"\x05as_of\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"R\n" +
+	"\x12RefreshUserRequest\x12\x1b\n" +
+	"\tuser_hash\x18\x01 \x01(\tR\buserHash\x12\x1f\n" +
+	"\vinclude_pii\x18\x02 \x01(\bR\n" +
+	"includePii\"\xa9\x01\n" +
+	"\x15GetUserHistoryRequest\x12\x1b\n" +
+	"\tuser_hash\x18\x01 \x01(\tR\buserHash\x12\x1f\n" +
+	"\vinclude_pii\x18\x02 \x01(\bR\n" +
+	"includePii\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"p\n" +
+	"\x16GetUserHistoryResponse\x12.\n" +
+	"\bversions\x18\x01 \x03(\v2\x12.users.UserVersionR\bversions\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xc8\x01\n" +
+	"\vUserVersion\x12;\n" +
+	"\vchange_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"changeTime\x126\n" +
+	"\vchange_type\x18\x02 \x01(\x0e2\x15.users.UserChangeTypeR\n" +
+	"changeType\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\x12,\n" +
+	"\achanges\x18\x04 \x03(\v2\x12.users.FieldChangeR\achanges\"\x83\x01\n" +
+	"\vFieldChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12 \n" +
+	"\told_value\x18\x02 \x01(\tH\x00R\boldValue\x88\x01\x01\x12 \n" +
+	"\tnew_value\x18\x03 \x01(\tH\x01R\bnewValue\x88\x01\x01B\f\n" +
+	"\n" +
+	"_old_valueB\f\n" +
+	"\n" +
+	"_new_value\"\x7f\n" +
+	"\x10GetPersonRequest\x12!\n" +
+	"\vperson_hash\x18\x01 \x01(\tH\x00R\n" +
+	"personHash\x12\x1d\n" +
+	"\tuser_hash\x18\x02 \x01(\tH\x00R\buserHash\x12\x1f\n" +
+	"\vinclude_pii\x18\x03 \x01(\bR\n" +
+	"includePiiB\b\n" +
+	"\x06lookup\"5\n" +
+	"\x12ListPersonsRequest\x12\x1f\n" +
+	"\vinclude_pii\x18\x01 \x01(\bR\n" +
+	"includePii\"R\n" +
+	"\x06Person\x12\x1f\n" +
+	"\vperson_hash\x18\x01 \x01(\tR\n" +
+	"personHash\x12'\n" +
+	"\baccounts\x18\x02 \x03(\v2\v.users.UserR\baccounts\"\xe3\x04\n" +
 	"\x04User\x12\x1b\n" +
 	"\tuser_hash\x18\x01 \x01(\tR\buserHash\x12.\n" +
 	"\buser_pii\x18\x02 \x01(\v2\x0e.users.UserPIIH\x00R\auserPii\x88\x01\x01\x12)\n" +
 	"\x06status\x18\x03 \x01(\x0e2\x11.users.UserStatusR\x06status\x126\n" +
-	"\bidp_type\x18\x04 \x01(\x0e2\x1b.users.IdentityProviderTypeR\aidpTypeB\v\n" +
-	"\t_user_pii\"\xbf\x04\n" +
+	"\bidp_type\x18\x04 \x01(\x0e2\x1b.users.IdentityProviderTypeR\aidpType\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x125\n" +
+	"\faccount_type\x18\f \x01(\x0e2\x12.users.AccountTypeR\vaccountType\x12B\n" +
+	"\x0flast_logon_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\rlastLogonTime\x12O\n" +
+	"\x16password_last_set_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x13passwordLastSetTime\x12;\n" +
+	"\vcreate_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12\x1e\n" +
+	"\n" +
+	"privileged\x18\t \x01(\bR\n" +
+	"privileged\x12'\n" +
+	"\x0fservice_account\x18\n" +
+	" \x01(\bR\x0eserviceAccount\x12$\n" +
+	"\vmfa_enabled\x18\v \x01(\bH\x01R\n" +
+	"mfaEnabled\x88\x01\x01B\v\n" +
+	"\t_user_piiB\x0e\n" +
+	"\f_mfa_enabled\"\xbf\x04\n" +
 	"\aUserPII\x12\x1f\n" +
 	"\busername\x18\x01 \x01(\tH\x00R\busername\x88\x01\x01\x12\x19\n" +
 	"\x05email\x18\x02 \x01(\tH\x01R\x05email\x88\x01\x01\x12&\n" +
@@ -564,21 +1355,47 @@ const file_users_users_proto_rawDesc = "" +
 	"\t_location\"H\n" +
 	"\tAttribute\x12%\n" +
 	"\x03key\x18\x01 \x01(\x0e2\x13.users.AttributeKeyR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value*[\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value*\x8c\x01\n" +
+	"\x0eUserChangeType\x12 \n" +
+	"\x1cUSER_CHANGE_TYPE_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18USER_CHANGE_TYPE_CREATED\x10\x01\x12\x1c\n" +
+	"\x18USER_CHANGE_TYPE_UPDATED\x10\x02\x12\x1c\n" +
+	"\x18USER_CHANGE_TYPE_DELETED\x10\x03*\xe0\x01\n" +
 	"\n" +
 	"UserStatus\x12\x1b\n" +
 	"\x17USER_STATUS_UNSPECIFIED\x10\x00\x12\x16\n" +
 	"\x12USER_STATUS_ACTIVE\x10\x01\x12\x18\n" +
-	"\x14USER_STATUS_DISABLED\x10\x02*-\n" +
+	"\x14USER_STATUS_DISABLED\x10\x02\x12\x16\n" +
+	"\x12USER_STATUS_LOCKED\x10\x03\x12\x17\n" +
+	"\x13USER_STATUS_EXPIRED\x10\x04\x12 \n" +
+	"\x1cUSER_STATUS_PASSWORD_EXPIRED\x10\x05\x12\x17\n" +
+	"\x13USER_STATUS_PENDING\x10\x06\x12\x17\n" +
+	"\x13USER_STATUS_DELETED\x10\a*S\n" +
 	"\fAttributeKey\x12\x1d\n" +
-	"\x19ATTRIBUTE_KEY_UNSPECIFIED\x10\x00*\x8c\x01\n" +
+	"\x19ATTRIBUTE_KEY_UNSPECIFIED\x10\x00\x12$\n" +
+	" ATTRIBUTE_KEY_DISTINGUISHED_NAME\x10\x01*\x93\x01\n" +
+	"\vAccountType\x12\x1c\n" +
+	"\x18ACCOUNT_TYPE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12ACCOUNT_TYPE_HUMAN\x10\x01\x12\x18\n" +
+	"\x14ACCOUNT_TYPE_SERVICE\x10\x02\x12\x17\n" +
+	"\x13ACCOUNT_TYPE_SHARED\x10\x03\x12\x1b\n" +
+	"\x17ACCOUNT_TYPE_PRIVILEGED\x10\x04*\xbf\x02\n" +
 	"\x14IdentityProviderType\x12&\n" +
 	"\"IDENTITY_PROVIDER_TYPE_UNSPECIFIED\x10\x00\x12+\n" +
 	"'IDENTITY_PROVIDER_TYPE_ACTIVE_DIRECTORY\x10\x01\x12\x1f\n" +
-	"\x1bIDENTITY_PROVIDER_TYPE_LDAP\x10\x022t\n" +
+	"\x1bIDENTITY_PROVIDER_TYPE_LDAP\x10\x02\x12\x1f\n" +
+	"\x1bIDENTITY_PROVIDER_TYPE_SCIM\x10\x03\x12#\n" +
+	"\x1fIDENTITY_PROVIDER_TYPE_ENTRA_ID\x10\x04\x12+\n" +
+	"'IDENTITY_PROVIDER_TYPE_GOOGLE_WORKSPACE\x10\x05\x12\x1f\n" +
+	"\x1bIDENTITY_PROVIDER_TYPE_FILE\x10\x06\x12\x1d\n" +
+	"\x19IDENTITY_PROVIDER_TYPE_HR\x10\a2\xec\x02\n" +
 	"\fUsersService\x125\n" +
 	"\tListUsers\x12\x17.users.ListUsersRequest\x1a\v.users.User\"\x000\x01\x12-\n" +
-	"\aGetUser\x12\x15.users.GetUserRequest\x1a\v.users.UserB\bZ\x06pkg/pbb\x06proto3"
+	"\aGetUser\x12\x15.users.GetUserRequest\x1a\v.users.User\x125\n" +
+	"\vRefreshUser\x12\x19.users.RefreshUserRequest\x1a\v.users.User\x12M\n" +
+	"\x0eGetUserHistory\x12\x1c.users.GetUserHistoryRequest\x1a\x1d.users.GetUserHistoryResponse\x123\n" +
+	"\tGetPerson\x12\x17.users.GetPersonRequest\x1a\r.users.Person\x12;\n" +
+	"\vListPersons\x12\x19.users.ListPersonsRequest\x1a\r.users.Person\"\x000\x01B\bZ\x06pkg/pbb\x06proto3"
 
 var (
 	file_users_users_proto_rawDescOnce sync.Once
@@ -592,33 +1409,64 @@ func file_users_users_proto_rawDescGZIP() []byte {
 	return file_users_users_proto_rawDescData
 }
 
-var file_users_users_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_users_users_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_users_users_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_users_users_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_users_users_proto_goTypes = []any{
-	(UserStatus)(0),           // 0: users.UserStatus
-	(AttributeKey)(0),         // 1: users.AttributeKey
-	(IdentityProviderType)(0), // 2: users.IdentityProviderType
-	(*ListUsersRequest)(nil),  // 3: users.ListUsersRequest
-	(*GetUserRequest)(nil),    // 4: users.GetUserRequest
-	(*User)(nil),              // 5: users.User
-	(*UserPII)(nil),           // 6: users.UserPII
-	(*Attribute)(nil),         // 7: users.Attribute
+	(UserChangeType)(0),            // 0: users.UserChangeType
+	(UserStatus)(0),                // 1: users.UserStatus
+	(AttributeKey)(0),              // 2: users.AttributeKey
+	(AccountType)(0),               // 3: users.AccountType
+	(IdentityProviderType)(0),      // 4: users.IdentityProviderType
+	(*ListUsersRequest)(nil),       // 5: users.ListUsersRequest
+	(*GetUserRequest)(nil),         // 6: users.GetUserRequest
+	(*RefreshUserRequest)(nil),     // 7: users.RefreshUserRequest
+	(*GetUserHistoryRequest)(nil),  // 8: users.GetUserHistoryRequest
+	(*GetUserHistoryResponse)(nil), // 9: users.GetUserHistoryResponse
+	(*UserVersion)(nil),            // 10: users.UserVersion
+	(*FieldChange)(nil),            // 11: users.FieldChange
+	(*GetPersonRequest)(nil),       // 12: users.GetPersonRequest
+	(*ListPersonsRequest)(nil),     // 13: users.ListPersonsRequest
+	(*Person)(nil),                 // 14: users.Person
+	(*User)(nil),                   // 15: users.User
+	(*UserPII)(nil),                // 16: users.UserPII
+	(*Attribute)(nil),              // 17: users.Attribute
+	(*timestamppb.Timestamp)(nil),  // 18: google.protobuf.Timestamp
 }
 var file_users_users_proto_depIdxs = []int32{
-	6, // 0: users.User.user_pii:type_name -> users.UserPII
-	0, // 1: users.User.status:type_name -> users.UserStatus
-	2, // 2: users.User.idp_type:type_name -> users.IdentityProviderType
-	7, // 3: users.UserPII.attributes:type_name -> users.Attribute
-	1, // 4: users.Attribute.key:type_name -> users.AttributeKey
-	3, // 5: users.UsersService.ListUsers:input_type -> users.ListUsersRequest
-	4, // 6: users.UsersService.GetUser:input_type -> users.GetUserRequest
-	5, // 7: users.UsersService.ListUsers:output_type -> users.User
-	5, // 8: users.UsersService.GetUser:output_type -> users.User
-	7, // [7:9] is the sub-list for method output_type
-	5, // [5:7] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	3,  // 0: users.ListUsersRequest.account_types:type_name -> users.AccountType
+	18, // 1: users.ListUsersRequest.as_of:type_name -> google.protobuf.Timestamp
+	18, // 2: users.GetUserRequest.as_of:type_name -> google.protobuf.Timestamp
+	10, // 3: users.GetUserHistoryResponse.versions:type_name -> users.UserVersion
+	18, // 4: users.UserVersion.change_time:type_name -> google.protobuf.Timestamp
+	0,  // 5: users.UserVersion.change_type:type_name -> users.UserChangeType
+	11, // 6: users.UserVersion.changes:type_name -> users.FieldChange
+	15, // 7: users.Person.accounts:type_name -> users.User
+	16, // 8: users.User.user_pii:type_name -> users.UserPII
+	1,  // 9: users.User.status:type_name -> users.UserStatus
+	4,  // 10: users.User.idp_type:type_name -> users.IdentityProviderType
+	3,  // 11: users.User.account_type:type_name -> users.AccountType
+	18, // 12: users.User.last_logon_time:type_name -> google.protobuf.Timestamp
+	18, // 13: users.User.password_last_set_time:type_name -> google.protobuf.Timestamp
+	18, // 14: users.User.create_time:type_name -> google.protobuf.Timestamp
+	17, // 15: users.UserPII.attributes:type_name -> users.Attribute
+	2,  // 16: users.Attribute.key:type_name -> users.AttributeKey
+	5,  // 17: users.UsersService.ListUsers:input_type -> users.ListUsersRequest
+	6,  // 18: users.UsersService.GetUser:input_type -> users.GetUserRequest
+	7,  // 19: users.UsersService.RefreshUser:input_type -> users.RefreshUserRequest
+	8,  // 20: users.UsersService.GetUserHistory:input_type -> users.GetUserHistoryRequest
+	12, // 21: users.UsersService.GetPerson:input_type -> users.GetPersonRequest
+	13, // 22: users.UsersService.ListPersons:input_type -> users.ListPersonsRequest
+	15, // 23: users.UsersService.ListUsers:output_type -> users.User
+	15, // 24: users.UsersService.GetUser:output_type -> users.User
+	15, // 25: users.UsersService.RefreshUser:output_type -> users.User
+	9,  // 26: users.UsersService.GetUserHistory:output_type -> users.GetUserHistoryResponse
+	14, // 27: users.UsersService.GetPerson:output_type -> users.Person
+	14, // 28: users.UsersService.ListPersons:output_type -> users.Person
+	23, // [23:29] is the sub-list for method output_type
+	17, // [17:23] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_users_users_proto_init() }
@@ -626,15 +1474,20 @@ func file_users_users_proto_init() {
 	if File_users_users_proto != nil {
 		return
 	}
-	file_users_users_proto_msgTypes[2].OneofWrappers = []any{}
-	file_users_users_proto_msgTypes[3].OneofWrappers = []any{}
+	file_users_users_proto_msgTypes[6].OneofWrappers = []any{}
+	file_users_users_proto_msgTypes[7].OneofWrappers = []any{
+		(*GetPersonRequest_PersonHash)(nil),
+		(*GetPersonRequest_UserHash)(nil),
+	}
+	file_users_users_proto_msgTypes[10].OneofWrappers = []any{}
+	file_users_users_proto_msgTypes[11].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_users_users_proto_rawDesc), len(file_users_users_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   5,
+			NumEnums:      5,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,8 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UsersService_ListUsers_FullMethodName = "/users.UsersService/ListUsers"
-	UsersService_GetUser_FullMethodName   = "/users.UsersService/GetUser"
+	UsersService_ListUsers_FullMethodName      = "/users.UsersService/ListUsers"
+	UsersService_GetUser_FullMethodName        = "/users.UsersService/GetUser"
+	UsersService_RefreshUser_FullMethodName    = "/users.UsersService/RefreshUser"
+	UsersService_GetUserHistory_FullMethodName = "/users.UsersService/GetUserHistory"
+	UsersService_GetPerson_FullMethodName      = "/users.UsersService/GetPerson"
+	UsersService_ListPersons_FullMethodName    = "/users.UsersService/ListPersons"
 )
 
 // UsersServiceClient is the client API for UsersService service.
@@ -29,6 +33,16 @@ const (
 type UsersServiceClient interface {
 	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[User], error)
 	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// RefreshUser reads one user again from its identity provider, stores and
+	// returns it. NOT_FOUND when the provider no longer has the user, whose
+	// record is then removed.
+	RefreshUser(ctx context.Context, in *RefreshUserRequest, opts ...grpc.CallOption) (*User, error)
+	// GetUserHistory lists the recorded changes of one user, newest first,
+	// including those before its removal. FAILED_PRECONDITION when user
+	// history is not enabled.
+	GetUserHistory(ctx context.Context, in *GetUserHistoryRequest, opts ...grpc.CallOption) (*GetUserHistoryResponse, error)
+	GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error)
+	ListPersons(ctx context.Context, in *ListPersonsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Person], error)
 }
 
 type usersServiceClient struct {
@@ -68,12 +82,71 @@ func (c *usersServiceClient) GetUser(ctx context.Context, in *GetUserRequest, op
 	return out, nil
 }
 
+func (c *usersServiceClient) RefreshUser(ctx context.Context, in *RefreshUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, UsersService_RefreshUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) GetUserHistory(ctx context.Context, in *GetUserHistoryRequest, opts ...grpc.CallOption) (*GetUserHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserHistoryResponse)
+	err := c.cc.Invoke(ctx, UsersService_GetUserHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Person)
+	err := c.cc.Invoke(ctx, UsersService_GetPerson_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) ListPersons(ctx context.Context, in *ListPersonsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Person], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UsersService_ServiceDesc.Streams[1], UsersService_ListPersons_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListPersonsRequest, Person]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UsersService_ListPersonsClient = grpc.ServerStreamingClient[Person]
+
 // UsersServiceServer is the server API for UsersService service.
 // All implementations must embed UnimplementedUsersServiceServer
 // for forward compatibility.
 type UsersServiceServer interface {
 	ListUsers(*ListUsersRequest, grpc.ServerStreamingServer[User]) error
 	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// RefreshUser reads one user again from its identity provider, stores and
+	// returns it. NOT_FOUND when the provider no longer has the user, whose
+	// record is then removed.
+	RefreshUser(context.Context, *RefreshUserRequest) (*User, error)
+	// GetUserHistory lists the recorded changes of one user, newest first,
+	// including those before its removal. FAILED_PRECONDITION when user
+	// history is not enabled.
+	GetUserHistory(context.Context, *GetUserHistoryRequest) (*GetUserHistoryResponse, error)
+	GetPerson(context.Context, *GetPersonRequest) (*Person, error)
+	ListPersons(*ListPersonsRequest, grpc.ServerStreamingServer[Person]) error
 	mustEmbedUnimplementedUsersServiceServer()
 }
 
@@ -90,6 +163,18 @@ func (UnimplementedUsersServiceServer) ListUsers(*ListUsersRequest, grpc.ServerS
 func (UnimplementedUsersServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
 }
+func (UnimplementedUsersServiceServer) RefreshUser(context.Context, *RefreshUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshUser not implemented")
+}
+func (UnimplementedUsersServiceServer) GetUserHistory(context.Context, *GetUserHistoryRequest) (*GetUserHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserHistory not implemented")
+}
+func (UnimplementedUsersServiceServer) GetPerson(context.Context, *GetPersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerson not implemented")
+}
+func (UnimplementedUsersServiceServer) ListPersons(*ListPersonsRequest, grpc.ServerStreamingServer[Person]) error {
+	return status.Errorf(codes.Unimplemented, "method ListPersons not implemented")
+}
 func (UnimplementedUsersServiceServer) mustEmbedUnimplementedUsersServiceServer() {}
 func (UnimplementedUsersServiceServer) testEmbeddedByValue()                      {}
 
@@ -140,6 +225,71 @@ func _UsersService_GetUser_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UsersService_RefreshUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).RefreshUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_RefreshUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).RefreshUser(ctx, req.(*RefreshUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_GetUserHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).GetUserHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_GetUserHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).GetUserHistory(ctx, req.(*GetUserHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_GetPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).GetPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_GetPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).GetPerson(ctx, req.(*GetPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_ListPersons_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPersonsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UsersServiceServer).ListPersons(m, &grpc.GenericServerStream[ListPersonsRequest, Person]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UsersService_ListPersonsServer = grpc.ServerStreamingServer[Person]
+
 // UsersService_ServiceDesc is the grpc.ServiceDesc for UsersService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -151,6 +301,18 @@ var UsersService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUser",
 			Handler:    _UsersService_GetUser_Handler,
 		},
+		{
+			MethodName: "RefreshUser",
+			Handler:    _UsersService_RefreshUser_Handler,
+		},
+		{
+			MethodName: "GetUserHistory",
+			Handler:    _UsersService_GetUserHistory_Handler,
+		},
+		{
+			MethodName: "GetPerson",
+			Handler:    _UsersService_GetPerson_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -158,6 +320,11 @@ var UsersService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _UsersService_ListUsers_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ListPersons",
+			Handler:       _UsersService_ListPersons_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "users/users.proto",
 }